@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-kube/pkg/misim"
+	"go-kube/pkg/replay"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Replays the pod and node changes of Kubernetes audit logs against the adapter, see the README
+func main() {
+	klog.InitFlags(nil)
+	defer klog.Flush()
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] audit.log...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	adapter := flag.String("adapter", "http://localhost:8000", "base URL of the adapter, e.g. http://localhost:8000/clusters/experiment")
+	var options replay.Options
+	flag.Float64Var(&options.Speed, "speed", 1, "replay speed relative to the recording, 0 replays without waiting")
+	flag.DurationVar(&options.Batch, "batch", time.Second, "changes within this recorded time are sent together and their pods placed in one round")
+	flag.BoolVar(&options.ReplayNodes, "replay-nodes", true, "replay the recorded node creates and deletes, disable it to leave the nodes to a cluster autoscaler")
+	waitComponents := flag.Duration("wait-components", time.Minute, "wait up to this time for the kube-scheduler and, if active, the cluster-autoscaler to connect, 0 disables the check")
+	comparisonFile := flag.String("comparison-file", "", "write the recorded and the live node of every pod to this JSONL file")
+	flag.Parse()
+	if flag.NArg() == 0 || options.Speed < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	changes, skipped, err := replay.ReadFiles(flag.Args())
+	if err != nil {
+		klog.Exit("Unable to read the audit log: ", err)
+	}
+	klog.V(1).Infof("Read %d changes", len(changes))
+	if skipped.WithoutObject > 0 {
+		klog.Warningf("Skipped %d creates and bindings without object, log pods and nodes at level Request or RequestResponse", skipped.WithoutObject)
+	}
+	if skipped.Invalid > 0 {
+		klog.Warningf("Skipped %d events that could not be decoded", skipped.Invalid)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	client := misim.NewClient(*adapter)
+	if *waitComponents > 0 {
+		readiness, err := client.ComponentsReady(ctx, nil, *waitComponents)
+		if err != nil {
+			klog.Exit("Unable to check the components: ", err)
+		}
+		if !readiness.Ready {
+			klog.Exitf("Components are not ready: %v", readiness.Missing)
+		}
+	}
+
+	replayer := replay.NewReplayer(client, options)
+	runErr := replayer.Run(ctx, changes)
+	if *comparisonFile != "" {
+		if err := writeComparisons(*comparisonFile, replayer.Comparisons()); err != nil {
+			klog.ErrorS(err, "Unable to write the comparison file")
+		}
+	}
+	summary, _ := json.MarshalIndent(replayer.Summary(), "", "  ")
+	fmt.Println(string(summary))
+	if runErr != nil {
+		klog.ErrorS(runErr, "Replay failed")
+		klog.Flush()
+		os.Exit(1)
+	}
+}
+
+func writeComparisons(path string, comparisons []replay.PodComparison) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	for _, comparison := range comparisons {
+		if err := encoder.Encode(comparison); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
@@ -1,47 +1,99 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"go-kube/internal/broadcast"
+	"go-kube/pkg/audit"
+	"go-kube/pkg/bootstrap"
+	"go-kube/pkg/config"
+	"go-kube/pkg/control"
 	"go-kube/pkg/interfaces"
-	"go-kube/pkg/storage"
+	"go-kube/pkg/results"
 	"go-kube/pkg/storage/inmemorystorage"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"k8s.io/klog/v2"
 )
 
-func initStorages() storage.StorageContainer {
-	var podStorage = inmemorystorage.NewPodInMemoryStorage()
-	var nodeStorage = inmemorystorage.NewNodeInMemoryStorage()
-	var namespaceStorage = inmemorystorage.NewNamespaceInMemoryStorage()
-	var daemonSetStorage = inmemorystorage.NewDaemonSetInMemoryStorage()
-	var machineStorage = inmemorystorage.NewMachineInMemoryStorage()
-	var machineSetStorage = inmemorystorage.NewMachineSetInMemoryStorage(&nodeStorage, &machineStorage)
-	var statusConfigMapStorage = inmemorystorage.NewStatusMapInMemoryStorage()
-	var podIdStorage = inmemorystorage.NewIdInMemoryStorage()
-	var machineIdStorage = inmemorystorage.NewIdInMemoryStorage()
-	var adapterStateStorage = inmemorystorage.NewAdapterStateInMemoryStorage()
-	var eventStorage = inmemorystorage.NewEventInMemoryStorage()
-
-	return storage.StorageContainer{
-		Pods:            &podStorage,
-		Nodes:           &nodeStorage,
-		Namespaces:      &namespaceStorage,
-		DaemonSets:      &daemonSetStorage,
-		Machines:        &machineStorage,
-		MachineSets:     &machineSetStorage,
-		StatusConfigMap: &statusConfigMapStorage,
-		PodIds:          &podIdStorage,
-		MachineIds:      &machineIdStorage,
-		AdapterState:    &adapterStateStorage,
-		Events:          &eventStorage,
-	}
-}
-
 func main() {
 	klog.InitFlags(nil) // initializing the flags
 	defer klog.Flush()  // flushes all pending log I/O
-	flag.Parse()        // parses the command-line flags
-	var storages = initStorages()
-	var app = interfaces.NewAdapterApplication(&storages)
-	app.Start()
+	var options interfaces.AdapterOptions
+	configFile := flag.String("config", "", "YAML config file of the adapter, see the README")
+	flag.BoolVar(&options.StrictValidation, "strict-validation", false, "reject simulator requests with validation warnings, not only with errors")
+	resultsFile := flag.String("results-file", "", "append the outcome of every simulator round to this file")
+	resultsFormat := flag.String("results-format", string(results.JSONL), "format of the results file, csv or jsonl")
+	auditLogPath := flag.String("audit-log-path", "", "append an audit.k8s.io/v1 event for every request to the Kubernetes API to this file")
+	auditPolicyFile := flag.String("audit-policy-file", "", "audit policy deciding the level of the requests, without it every request is logged at level Metadata")
+	flag.Parse() // parses the command-line flags
+	options.Config = config.Default()
+	if *configFile != "" {
+		adapterConfig, err := config.Load(*configFile)
+		if err != nil {
+			klog.Exit(err)
+		}
+		options.Config = adapterConfig
+	}
+	if *resultsFile != "" {
+		recorder, err := results.Open(*resultsFile, results.Format(*resultsFormat))
+		if err != nil {
+			klog.Exit("Unable to open the results file: ", err)
+		}
+		options.Results = recorder
+	}
+	if *auditLogPath != "" {
+		policy := audit.DefaultPolicy()
+		if *auditPolicyFile != "" {
+			var err error
+			if policy, err = audit.LoadPolicy(*auditPolicyFile); err != nil {
+				klog.Exit(err)
+			}
+		}
+		logger, err := audit.Open(*auditLogPath, policy)
+		if err != nil {
+			klog.Exit("Unable to open the audit log: ", err)
+		}
+		options.Audit = logger
+	} else if *auditPolicyFile != "" {
+		klog.Exit("--audit-policy-file requires --audit-log-path")
+	}
+	// Stops the broadcasters of the storages, the adapter cancels it when it shuts down
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var storages = inmemorystorage.NewStorageContainer(broadcast.WithCluster(ctx, interfaces.DefaultCluster), options.Config.Namespaces, options.Config.TimelineMaxEvents)
+	if len(options.Config.BootstrapManifests) > 0 {
+		manifests, err := bootstrap.ReadFiles(options.Config.BootstrapManifests)
+		if err != nil {
+			klog.Exit("Unable to read the bootstrap manifests: ", err)
+		}
+		if _, err := control.NewBootstrapResource(&storages).Load(manifests); err != nil {
+			klog.Exit("Unable to load the bootstrap manifests: ", err)
+		}
+	}
+	var app = interfaces.NewAdapterApplication(ctx, &storages, options)
+	// The first signal shuts the adapter down gracefully, a second one terminates it at once
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals.Done()
+		stopSignals()
+	}()
+	err := app.Start(signals, cancel)
+	if options.Results != nil {
+		if closeErr := options.Results.Close(); closeErr != nil {
+			klog.ErrorS(closeErr, "Unable to close the results file")
+		}
+	}
+	if options.Audit != nil {
+		if closeErr := options.Audit.Close(); closeErr != nil {
+			klog.ErrorS(closeErr, "Unable to close the audit log")
+		}
+	}
+	if err != nil {
+		klog.ErrorS(err, "Adapter failed")
+		klog.Flush()
+		os.Exit(1)
+	}
 }
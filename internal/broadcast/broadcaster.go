@@ -3,79 +3,268 @@ package broadcast
 import (
 	"context"
 	"k8s.io/klog/v2"
+	"sync"
 )
 
 // https://betterprogramming.pub/how-to-broadcast-messages-in-go-using-channels-b68f42bdf32e
 
+// Number of messages queued for a single subscriber. A subscriber that falls further
+// behind blocks the broadcaster and with it the writers of the source channel, unless
+// it subscribed with SubscribeDropIfSlow.
+const SubscriberQueueSize = 500
+
 type BroadcastServer[T any] struct {
-	source         <-chan T
-	listeners      []chan T
-	addListener    chan chan T
-	removeListener chan (<-chan T)
-	name           string
+	source <-chan T
+	name   string
+	// Virtual cluster whose storage owns the broadcaster, see WithCluster
+	cluster string
+
+	mu        sync.Mutex
+	listeners []*listener[T]
+	stopped   bool
+	published uint64
+	dropped   uint64
+	// Held while publishing. The listener channels are only closed with it held,
+	// so a message is never sent to a closed channel.
+	sendMu sync.Mutex
+}
+
+type listener[T any] struct {
+	channel  chan T
+	dropSlow bool
+	// Closed when the subscription is cancelled, unblocks a publish waiting for the listener
+	cancelled chan struct{}
 }
 
+// Returns a channel receiving all messages published from now on. The channel is
+// closed if the broadcaster is stopped. If the subscriber does not keep up, the
+// broadcaster waits for it.
 func (s *BroadcastServer[T]) Subscribe() <-chan T {
+	return s.subscribe(false)
+}
+
+// Like Subscribe, but the subscriber is dropped and its channel closed as soon as
+// its queue runs full, so it cannot stall the other subscribers and the writers of
+// the source channel. Used for watch clients, which relist in that case, and the simulator stream.
+func (s *BroadcastServer[T]) SubscribeDropIfSlow() <-chan T {
+	return s.subscribe(true)
+}
+
+func (s *BroadcastServer[T]) subscribe(dropSlow bool) <-chan T {
 	klog.V(7).Info("Subscribe to ", s.name)
-	newListener := make(chan T, 500)
-	s.addListener <- newListener
-	return newListener
+	newListener := &listener[T]{channel: make(chan T, SubscriberQueueSize), dropSlow: dropSlow, cancelled: make(chan struct{})}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		close(newListener.channel)
+		return newListener.channel
+	}
+	s.listeners = append(s.listeners, newListener)
+	return newListener.channel
 }
 
 func (s *BroadcastServer[T]) CancelSubscription(channel <-chan T) {
 	klog.V(7).Info("Remove from ", s.name)
-	s.removeListener <- channel
+	s.mu.Lock()
+	var cancelled *listener[T]
+	for i, l := range s.listeners {
+		if l.channel == channel {
+			cancelled = l
+			s.listeners[i] = s.listeners[len(s.listeners)-1]
+			s.listeners[len(s.listeners)-1] = nil
+			s.listeners = s.listeners[:len(s.listeners)-1]
+			close(l.cancelled)
+			break
+		}
+	}
+	s.mu.Unlock()
+	if cancelled == nil {
+		// Already removed because the subscriber was too slow or the broadcaster stopped
+		return
+	}
+	// A publish waiting for the listener returns now that it is cancelled
+	s.sendMu.Lock()
+	close(cancelled.channel)
+	s.sendMu.Unlock()
 }
 
 func NewBroadcastServer[T any](ctx context.Context, name string, source <-chan T) *BroadcastServer[T] {
 	service := &BroadcastServer[T]{
-		source:         source,
-		listeners:      make([]chan T, 0),
-		addListener:    make(chan chan T),
-		removeListener: make(chan (<-chan T)),
-		name:           name,
+		source:    source,
+		listeners: make([]*listener[T], 0),
+		name:      name,
+		cluster:   ClusterFromContext(ctx),
 	}
+	register(service)
 	go service.serve(ctx)
 	return service
 }
 
-func (s *BroadcastServer[T]) serve(ctx context.Context) {
-	defer func() {
-		for _, listener := range s.listeners {
-			if listener != nil {
-				close(listener)
-			}
+// Subscribes to source and publishes every message converted by convert. The returned
+// broadcaster stops as soon as its subscription to source is closed.
+func NewMappedBroadcastServer[S any, T any](source *BroadcastServer[S], name string, convert func(S) T) *BroadcastServer[T] {
+	subscription := source.Subscribe()
+	mapped := make(chan T, SubscriberQueueSize)
+	go func() {
+		defer close(mapped)
+		for message := range subscription {
+			mapped <- convert(message)
 		}
 	}()
+	return NewBroadcastServer(WithCluster(context.Background(), source.cluster), name, mapped)
+}
+
+type clusterKey struct{}
 
+// Returns a context whose broadcasters are attributed to the passed virtual cluster in their stats
+func WithCluster(ctx context.Context, cluster string) context.Context {
+	return context.WithValue(ctx, clusterKey{}, cluster)
+}
+
+// Returns the virtual cluster set with WithCluster, or an empty string
+func ClusterFromContext(ctx context.Context) string {
+	cluster, _ := ctx.Value(clusterKey{}).(string)
+	return cluster
+}
+
+func (s *BroadcastServer[T]) Name() string {
+	return s.name
+}
+
+func (s *BroadcastServer[T]) Cluster() string {
+	return s.cluster
+}
+
+func (s *BroadcastServer[T]) Stopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+func (s *BroadcastServer[T]) serve(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
+			s.publishQueued()
+			s.stop()
+			s.discard()
 			return
-		case newListener := <-s.addListener:
-			s.listeners = append(s.listeners, newListener)
-		case listenerToRemove := <-s.removeListener:
-			for i, ch := range s.listeners {
-				if ch == listenerToRemove {
-					s.listeners[i] = s.listeners[len(s.listeners)-1]
-					s.listeners = s.listeners[:len(s.listeners)-1]
-					close(ch)
-					break
-				}
+		case val, ok := <-s.source:
+			if !ok {
+				s.stop()
+				return
 			}
+			s.publish(val)
+		}
+	}
+}
+
+// Publishes the messages that were sent to the source before the broadcaster stopped,
+// e.g. the DELETED events of a virtual cluster that is deleted
+func (s *BroadcastServer[T]) publishQueued() {
+	for {
+		select {
 		case val, ok := <-s.source:
 			if !ok {
 				return
 			}
-			for _, listener := range s.listeners {
-				if listener != nil {
-					select {
-					case listener <- val:
-					case <-ctx.Done():
-						return
-					}
-				}
+			s.publish(val)
+		default:
+			return
+		}
+	}
+}
+
+// Reads the source until it is closed, so that writers never block on a stopped
+// broadcaster, e.g. the storages of a deleted virtual cluster that are still in use
+func (s *BroadcastServer[T]) discard() {
+	for range s.source {
+	}
+}
+
+func (s *BroadcastServer[T]) publish(val T) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	s.published++
+	listeners := make([]*listener[T], len(s.listeners))
+	copy(listeners, s.listeners)
+	s.mu.Unlock()
+
+	var slow []*listener[T]
+	for _, l := range listeners {
+		if l.dropSlow {
+			select {
+			case l.channel <- val:
+			default:
+				slow = append(slow, l)
+			}
+			continue
+		}
+		select {
+		case l.channel <- val:
+		case <-l.cancelled:
+		}
+	}
+	if len(slow) > 0 {
+		s.drop(slow)
+	}
+}
+
+// Removes the listeners whose queue is full, the caller holds sendMu
+func (s *BroadcastServer[T]) drop(slow []*listener[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range slow {
+		for i := range s.listeners {
+			if s.listeners[i] != l {
+				continue
 			}
+			s.listeners[i] = s.listeners[len(s.listeners)-1]
+			s.listeners[len(s.listeners)-1] = nil
+			s.listeners = s.listeners[:len(s.listeners)-1]
+			close(l.cancelled)
+			close(l.channel)
+			s.dropped++
+			klog.V(1).Infof("Dropped slow subscriber of %s with %d queued messages", s.name, len(l.channel))
+			break
+		}
+	}
+}
+
+func (s *BroadcastServer[T]) stop() {
+	unregister(s)
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	for _, l := range s.listeners {
+		close(l.cancelled)
+		close(l.channel)
+	}
+	s.listeners = nil
+	klog.V(4).Info("Stopped ", s.name)
+}
+
+func (s *BroadcastServer[T]) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := Stats{
+		Name:               s.name,
+		Cluster:            s.cluster,
+		Subscribers:        len(s.listeners),
+		QueueCapacity:      SubscriberQueueSize,
+		QueueDepths:        make([]int, len(s.listeners)),
+		SourceDepth:        len(s.source),
+		Published:          s.published,
+		DroppedSubscribers: s.dropped,
+	}
+	for i, l := range s.listeners {
+		stats.QueueDepths[i] = len(l.channel)
+		if len(l.channel) > stats.MaxQueueDepth {
+			stats.MaxQueueDepth = len(l.channel)
 		}
 	}
+	return stats
 }
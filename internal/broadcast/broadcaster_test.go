@@ -0,0 +1,92 @@
+package broadcast
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSlowSubscribers(t *testing.T) {
+	source := make(chan int)
+	server := NewBroadcastServer(context.Background(), "Test", source)
+	blocking := server.Subscribe()
+	dropping := server.SubscribeDropIfSlow()
+
+	// Neither subscriber reads, the dropping one is closed once its queue is full
+	for i := 0; i < SubscriberQueueSize+1; i++ {
+		source <- i
+	}
+	// The blocking subscriber holds the broadcaster, reading unblocks it
+	go func() { source <- SubscriberQueueSize + 1 }()
+	for i := 0; i < SubscriberQueueSize+2; i++ {
+		select {
+		case message := <-blocking:
+			if message != i {
+				t.Fatalf("blocking subscriber received %d, want %d", message, i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("blocking subscriber received only %d messages", i)
+		}
+	}
+	received := 0
+	for range dropping {
+		received++
+	}
+	if received != SubscriberQueueSize {
+		t.Fatalf("dropped subscriber received %d messages, want %d", received, SubscriberQueueSize)
+	}
+	if stats := server.Stats(); stats.DroppedSubscribers != 1 || stats.Subscribers != 1 {
+		t.Fatalf("stats show %d dropped of %d subscribers, want 1 of 1", stats.DroppedSubscribers, stats.Subscribers)
+	}
+}
+
+func TestCancelBlockedSubscription(t *testing.T) {
+	source := make(chan int)
+	server := NewBroadcastServer(context.Background(), "Test", source)
+	subscription := server.Subscribe()
+	for i := 0; i < SubscriberQueueSize+1; i++ {
+		source <- i
+	}
+	// The broadcaster waits for the subscriber, cancelling must not wait for the broadcaster
+	cancelled := make(chan struct{})
+	go func() {
+		server.CancelSubscription(subscription)
+		close(cancelled)
+	}()
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("cancelling a subscription blocked")
+	}
+	select {
+	case source <- 0:
+	case <-time.After(time.Second):
+		t.Fatal("broadcaster still blocked after the subscription was cancelled")
+	}
+}
+
+func TestStoppedBroadcasterDoesNotBlockWriters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	source := make(chan int, 1)
+	server := NewBroadcastServer(ctx, "Test", source)
+	subscription := server.Subscribe()
+	cancel()
+	if _, open := <-subscription; open {
+		t.Fatal("subscription still open after the broadcaster stopped")
+	}
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < SubscriberQueueSize*2; i++ {
+			source <- i
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("writing to a stopped broadcaster blocked")
+	}
+	if !server.Stopped() {
+		t.Fatal("broadcaster not stopped")
+	}
+}
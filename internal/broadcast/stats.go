@@ -0,0 +1,67 @@
+package broadcast
+
+import (
+	"sort"
+	"sync"
+)
+
+// Current state of a broadcaster
+type Stats struct {
+	Name        string `json:"name"`
+	Cluster     string `json:"cluster,omitempty"`
+	Subscribers int    `json:"subscribers"`
+	// Capacity of the queue of each subscriber
+	QueueCapacity int `json:"queueCapacity"`
+	// Number of messages queued for each subscriber
+	QueueDepths   []int `json:"queueDepths"`
+	MaxQueueDepth int   `json:"maxQueueDepth"`
+	// Number of messages waiting in the source channel
+	SourceDepth int    `json:"sourceDepth"`
+	Published   uint64 `json:"published"`
+	// Subscribers dropped because their queue was full
+	DroppedSubscribers uint64 `json:"droppedSubscribers"`
+}
+
+type statsProvider interface {
+	Stats() Stats
+}
+
+// Running broadcasters
+var registry = struct {
+	mu      sync.Mutex
+	servers map[statsProvider]struct{}
+}{servers: make(map[statsProvider]struct{})}
+
+func register(server statsProvider) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.servers[server] = struct{}{}
+}
+
+func unregister(server statsProvider) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	delete(registry.servers, server)
+}
+
+// Returns the stats of all running broadcasters, sorted by cluster and name
+func AllStats() []Stats {
+	registry.mu.Lock()
+	servers := make([]statsProvider, 0, len(registry.servers))
+	for server := range registry.servers {
+		servers = append(servers, server)
+	}
+	registry.mu.Unlock()
+
+	stats := make([]Stats, len(servers))
+	for i, server := range servers {
+		stats[i] = server.Stats()
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Cluster != stats[j].Cluster {
+			return stats[i].Cluster < stats[j].Cluster
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
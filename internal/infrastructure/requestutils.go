@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -60,3 +62,67 @@ func HandleRequestWithParams[T any](supplier func(map[string]string) T) Endpoint
 		json.NewEncoder(w).Encode(resourceList)
 	}
 }
+
+func HandleRequestWithParamsAndError[T any](supplier func(map[string]string) (T, error)) Endpoint {
+	return func(w http.ResponseWriter, r *http.Request) {
+		klog.V(7).Infof("Req: %s%s?%s", r.Host, r.URL.Path, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		resourceList, err := supplier(mux.Vars(r))
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(resourceList)
+	}
+}
+
+func HandleRequestWithJSONBodyAndError[B any, T any](supplier func(B) (T, error)) Endpoint {
+	return func(w http.ResponseWriter, r *http.Request) {
+		klog.V(7).Infof("Req: %s%s?%s", r.Host, r.URL.Path, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		reqBody, _ := io.ReadAll(r.Body)
+		var payload B
+		err := json.Unmarshal(reqBody, &payload)
+		if err != nil {
+			klog.V(1).ErrorS(err, "There was an error decoding the json. err = %s", err)
+			w.WriteHeader(500)
+			return
+		}
+		resourceList, err := supplier(payload)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(resourceList)
+	}
+}
+
+// Passes the raw request body to the supplier, e.g. for bodies that are not JSON
+func HandleRequestWithBodyAndError[T any](supplier func(io.Reader) (T, error)) Endpoint {
+	return func(w http.ResponseWriter, r *http.Request) {
+		klog.V(7).Infof("Req: %s%s?%s", r.Host, r.URL.Path, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		resourceList, err := supplier(r.Body)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		json.NewEncoder(w).Encode(resourceList)
+	}
+}
+
+// Writes the error as Kubernetes status object. Errors that do not carry
+// a status are reported as internal errors.
+func WriteError(w http.ResponseWriter, err error) {
+	var status metav1.Status
+	if apiStatus, ok := err.(apierrors.APIStatus); ok {
+		status = apiStatus.Status()
+	} else {
+		status = apierrors.NewInternalError(err).Status()
+	}
+	status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	klog.V(3).Infof("Request failed with %d: %s", status.Code, status.Message)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(status.Code))
+	json.NewEncoder(w).Encode(status)
+}
@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strings"
@@ -13,6 +14,7 @@ import (
 func UnsupportedResource() Endpoint {
 	return func(w http.ResponseWriter, r *http.Request) {
 		klog.V(7).Infof("Req: %s%s?%s", r.Host, r.URL.Path, r.URL.RawQuery)
+		MarkUnsupported(r)
 		w.Header().Set("Content-Type", "application/json")
 		if r.URL.Query().Get("watch") != "" {
 			ctx := r.Context()
@@ -56,3 +58,18 @@ func UnsupportedResource() Endpoint {
 		}
 	}
 }
+
+type unsupportedKey struct{}
+
+// Returns the request with a flag that is set if the request is not really served, see MarkUnsupported
+func TrackUnsupported(r *http.Request) (*http.Request, *bool) {
+	unsupported := new(bool)
+	return r.WithContext(context.WithValue(r.Context(), unsupportedKey{}, unsupported)), unsupported
+}
+
+// Flags a tracked request as answered without the adapter implementing its resource
+func MarkUnsupported(r *http.Request) {
+	if unsupported, ok := r.Context().Value(unsupportedKey{}).(*bool); ok {
+		*unsupported = true
+	}
+}
@@ -1,17 +1,19 @@
 package infrastructure
 
 import (
+	"context"
 	"encoding/json"
 	"go-kube/internal/broadcast"
+	"go-kube/internal/metrics"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"net/http"
+	"path"
 )
 
 func HandleWatchableRequest[T any](supplier func() (T, *broadcast.BroadcastServer[metav1.WatchEvent])) Endpoint {
 	return func(w http.ResponseWriter, r *http.Request) {
 		klog.V(7).Infof("Req: %s%s?%s", r.Host, r.URL.Path, r.URL.RawQuery)
-		w.Header().Set("Content-Type", "application/json")
 		resourceList, broadcastServer := supplier()
 		if r.URL.Query().Get("watch") != "" {
 			// watch initiated HTTP streaming answers
@@ -24,27 +26,44 @@ func HandleWatchableRequest[T any](supplier func() (T, *broadcast.BroadcastServe
 				http.NotFound(w, r)
 				return
 			}
+			// Events are encoded once and shared by all watchers of the resource
+			contentType := negotiateWatchContentType[T](r)
+			w.Header().Set("Content-Type", contentType)
+
 			// Send the initial headers saying we're gonna stream the response.
 			w.Header().Set("Transfer-Encoding", "chunked")
 			w.WriteHeader(http.StatusOK)
 			flusher.Flush()
 
-			enc := json.NewEncoder(w)
+			activeWatches := metrics.ActiveWatches.WithLabelValues(broadcastServer.Cluster(), path.Base(r.URL.Path))
+			activeWatches.Inc()
+			defer activeWatches.Dec()
 
-			eventChannel := broadcastServer.Subscribe()
-			defer broadcastServer.CancelSubscription(eventChannel)
+			encodedBroadcastServer := encodedBroadcaster(broadcastServer, listItemFactory[T]())
+			eventChannel := encodedBroadcastServer.SubscribeDropIfSlow()
+			defer encodedBroadcastServer.CancelSubscription(eventChannel)
 
-			klog.V(6).Infof("Client started listening (%s)...", r.URL.Path)
+			klog.V(6).Infof("Client started listening (%s, %s)...", r.URL.Path, contentType)
+			eventLimit, sent := watchEventLimit(r), 0
 			for {
 				klog.V(6).Infof("Client waits for result (%s)...", r.URL.Path)
 				select {
 				case <-ctx.Done():
 					klog.V(6).Infof("Client stopped listening (%s)", r.URL.Path)
 					return
-				case event := <-eventChannel:
-					klog.V(6).Infof("Received event for client (%s) of type %s", r.URL.Path, event.Type)
-					if err := enc.Encode(event); err != nil {
-						klog.V(1).ErrorS(err, "unable to encode watch object %T: %v", event, err)
+				case event, ok := <-eventChannel:
+					if !ok {
+						// The client was too slow or the adapter is stopping, ending the watch makes the client relist
+						klog.V(1).Infof("Watch of client closed by broadcaster (%s)", r.URL.Path)
+						return
+					}
+					klog.V(6).Infof("Received event for client (%s) of type %s", r.URL.Path, event.event.Type)
+					encodedEvent, err := event.encode(contentType)
+					if err != nil {
+						klog.V(1).ErrorS(err, "unable to encode watch object", "type", event.event.Type, "path", r.URL.Path)
+						return
+					}
+					if _, err := w.Write(encodedEvent); err != nil {
 						// client disconnect.
 						return
 					}
@@ -53,10 +72,16 @@ func HandleWatchableRequest[T any](supplier func() (T, *broadcast.BroadcastServe
 						klog.V(6).Infof("Client flushed (%s)!", r.URL.Path)
 						//return
 					}
+					if sent++; eventLimit > 0 && sent >= eventLimit {
+						flusher.Flush()
+						klog.V(4).Infof("Closed watch after %d events (%s)", sent, r.URL.Path)
+						return
+					}
 				}
 			}
 		} else {
 			// if no watch we just list the resource
+			w.Header().Set("Content-Type", "application/json")
 			err := json.NewEncoder(w).Encode(resourceList)
 			if err != nil {
 				klog.V(1).ErrorS(err, "unable to encode resource list, error is: %v", err)
@@ -65,3 +90,15 @@ func HandleWatchableRequest[T any](supplier func() (T, *broadcast.BroadcastServe
 		}
 	}
 }
+
+type watchEventLimitKey struct{}
+
+// Returns the request with a limit of watch events, its watch is closed after sending them
+func WithWatchEventLimit(r *http.Request, limit int) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), watchEventLimitKey{}, limit))
+}
+
+func watchEventLimit(r *http.Request) int {
+	limit, _ := r.Context().Value(watchEventLimitKey{}).(int)
+	return limit
+}
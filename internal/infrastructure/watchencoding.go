@@ -0,0 +1,163 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-kube/internal/broadcast"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const (
+	jsonWatchContentType     = "application/json"
+	protobufContentType      = "application/vnd.kubernetes.protobuf"
+	protobufWatchContentType = protobufContentType + ";stream=watch"
+)
+
+var (
+	protobufSerializer    = protobuf.NewSerializer(scheme.Scheme, scheme.Scheme)
+	protobufRawSerializer = protobuf.NewRawSerializer(scheme.Scheme, scheme.Scheme)
+)
+
+// Watch event that is encoded at most once per content type. The same instance
+// is passed to all watchers, so they share the encoded bytes.
+type encodedWatchEvent struct {
+	event metav1.WatchEvent
+	// Creates an empty object of the watched kind, used to decode events that only carry raw JSON
+	newObject func() runtime.Object
+
+	json     lazyEncoding
+	protobuf lazyEncoding
+}
+
+type lazyEncoding struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+func (e *encodedWatchEvent) encode(contentType string) ([]byte, error) {
+	if contentType == protobufWatchContentType {
+		e.protobuf.once.Do(func() {
+			e.protobuf.data, e.protobuf.err = e.encodeProtobuf()
+		})
+		return e.protobuf.data, e.protobuf.err
+	}
+	e.json.once.Do(func() {
+		e.json.data, e.json.err = json.Marshal(e.event)
+		// Like json.Encoder, every event is terminated by a newline
+		e.json.data = append(e.json.data, '\n')
+	})
+	return e.json.data, e.json.err
+}
+
+// Encodes the event like the Kubernetes API server: the object is a protobuf message
+// embedded in a WatchEvent, which is written as length-delimited frame
+func (e *encodedWatchEvent) encodeProtobuf() ([]byte, error) {
+	var object runtime.Object
+	if e.event.Object.Object != nil {
+		// Setting the kind must not change the object shared with the JSON encoding
+		object = e.event.Object.Object.DeepCopyObject()
+	} else {
+		if e.newObject == nil {
+			return nil, fmt.Errorf("unable to decode raw object of %s event", e.event.Type)
+		}
+		object = e.newObject()
+		if err := json.Unmarshal(e.event.Object.Raw, object); err != nil {
+			return nil, err
+		}
+	}
+	if _, _, err := scheme.Scheme.ObjectKinds(object); err != nil {
+		return nil, err
+	}
+	object = withKind(object)
+	var embedded bytes.Buffer
+	if err := protobufSerializer.Encode(object, &embedded); err != nil {
+		return nil, err
+	}
+
+	var frame bytes.Buffer
+	frameWriter := protobuf.LengthDelimitedFramer.NewFrameWriter(&frame)
+	outEvent := &metav1.WatchEvent{Type: e.event.Type, Object: runtime.RawExtension{Raw: embedded.Bytes()}}
+	if err := protobufRawSerializer.Encode(outEvent, frameWriter); err != nil {
+		return nil, err
+	}
+	return frame.Bytes(), nil
+}
+
+// Sets the kind of objects known to the scheme, other objects are returned unchanged
+func withKind(object runtime.Object) runtime.Object {
+	if kinds, _, err := scheme.Scheme.ObjectKinds(object); err == nil {
+		object.GetObjectKind().SetGroupVersionKind(kinds[0])
+	}
+	return object
+}
+
+// Broadcasters of encoded events, one per source broadcaster
+var encodedBroadcasters = struct {
+	mu      sync.Mutex
+	servers map[*broadcast.BroadcastServer[metav1.WatchEvent]]*broadcast.BroadcastServer[*encodedWatchEvent]
+}{servers: make(map[*broadcast.BroadcastServer[metav1.WatchEvent]]*broadcast.BroadcastServer[*encodedWatchEvent])}
+
+// Returns the broadcaster of encoded events for the passed source, it is created with the first watch
+func encodedBroadcaster(source *broadcast.BroadcastServer[metav1.WatchEvent], newObject func() runtime.Object) *broadcast.BroadcastServer[*encodedWatchEvent] {
+	encodedBroadcasters.mu.Lock()
+	defer encodedBroadcasters.mu.Unlock()
+	if server, found := encodedBroadcasters.servers[source]; found && !server.Stopped() {
+		return server
+	}
+	// Sources stop with their virtual cluster, their entries would never be looked up again
+	for stoppedSource, server := range encodedBroadcasters.servers {
+		if server.Stopped() {
+			delete(encodedBroadcasters.servers, stoppedSource)
+		}
+	}
+	server := broadcast.NewMappedBroadcastServer(source, "Encoded"+source.Name(), func(event metav1.WatchEvent) *encodedWatchEvent {
+		return &encodedWatchEvent{event: event, newObject: newObject}
+	})
+	encodedBroadcasters.servers[source] = server
+	return server
+}
+
+// Returns a function creating an empty item of the passed list type, e.g. a v1.Pod for a v1.PodList
+func listItemFactory[T any]() func() runtime.Object {
+	itemType := reflect.TypeOf((*T)(nil)).Elem()
+	items, found := itemType.FieldByName("Items")
+	if !found {
+		return nil
+	}
+	return func() runtime.Object {
+		object, _ := reflect.New(items.Type.Elem()).Interface().(runtime.Object)
+		return object
+	}
+}
+
+// Serves protobuf if the client accepts it and the resource supports it, JSON otherwise
+func negotiateWatchContentType[T any](r *http.Request) string {
+	if _, ok := any(new(T)).(interface{ Marshal() ([]byte, error) }); !ok {
+		return jsonWatchContentType
+	}
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		// The first supported type wins
+		switch mediaType {
+		case protobufContentType:
+			return protobufWatchContentType
+		case jsonWatchContentType, "application/*", "*/*":
+			return jsonWatchContentType
+		}
+	}
+	return jsonWatchContentType
+}
@@ -0,0 +1,224 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go-kube/internal/broadcast"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+	"k8s.io/apimachinery/pkg/runtime/serializer/streaming"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	restclientwatch "k8s.io/client-go/rest/watch"
+	cluster "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Number of components watching the same resource, e.g. schedulers, autoscalers and controllers
+const watchers = 50
+
+func benchmarkPod(i int) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("pod-%d", i),
+			Namespace:       "default",
+			ResourceVersion: fmt.Sprint(i),
+			Labels:          map[string]string{"app": "benchmark", "tier": "backend"},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node-1",
+			Containers: []v1.Container{{
+				Name:  "app",
+				Image: "registry.example.com/app:1.0",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("250m"),
+					v1.ResourceMemory: resource.MustParse("512Mi"),
+				}},
+			}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+// Publishes b.N events to the watchers, each writing every event it receives
+func benchmarkWatchers[T any](b *testing.B, subscribe func() (<-chan T, func()), publish func(i int), write func(event T, w io.Writer) error) {
+	var ready, done sync.WaitGroup
+	for watcher := 0; watcher < watchers; watcher++ {
+		subscription, cancel := subscribe()
+		ready.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			defer cancel()
+			ready.Done()
+			for received := 0; received < b.N; received++ {
+				if err := write(<-subscription, io.Discard); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	ready.Wait()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		publish(i)
+	}
+	done.Wait()
+}
+
+// Compares encoding every event for each watcher, as before, with encoding it once and sharing the bytes
+func BenchmarkWatchEncoding(b *testing.B) {
+	b.Run("PerSubscriberJSON", func(b *testing.B) {
+		source := make(chan metav1.WatchEvent, broadcast.SubscriberQueueSize)
+		server := broadcast.NewBroadcastServer(context.Background(), "Benchmark", source)
+		benchmarkWatchers(b, func() (<-chan metav1.WatchEvent, func()) {
+			subscription := server.Subscribe()
+			return subscription, func() { server.CancelSubscription(subscription) }
+		}, func(i int) {
+			source <- metav1.WatchEvent{Type: "MODIFIED", Object: runtime.RawExtension{Object: benchmarkPod(i)}}
+		}, func(event metav1.WatchEvent, w io.Writer) error {
+			return json.NewEncoder(w).Encode(event)
+		})
+	})
+	for _, shared := range []struct{ name, contentType string }{
+		{"SharedJSON", jsonWatchContentType},
+		{"SharedProtobuf", protobufWatchContentType},
+	} {
+		contentType := shared.contentType
+		b.Run(shared.name, func(b *testing.B) {
+			source := make(chan metav1.WatchEvent, broadcast.SubscriberQueueSize)
+			server := encodedBroadcaster(broadcast.NewBroadcastServer(context.Background(), "Benchmark", source), listItemFactory[v1.PodList]())
+			benchmarkWatchers(b, func() (<-chan *encodedWatchEvent, func()) {
+				subscription := server.Subscribe()
+				return subscription, func() { server.CancelSubscription(subscription) }
+			}, func(i int) {
+				source <- metav1.WatchEvent{Type: "MODIFIED", Object: runtime.RawExtension{Object: benchmarkPod(i)}}
+			}, func(event *encodedWatchEvent, w io.Writer) error {
+				data, err := event.encode(contentType)
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(data)
+				return err
+			})
+		})
+	}
+}
+
+// Decodes a single encoded event like client-go does for watches of the passed content type
+func decodeWatchEvent(t *testing.T, contentType string, data []byte, embeddedDecoder runtime.Decoder) (watch.EventType, runtime.Object) {
+	t.Helper()
+	stream := io.NopCloser(bytes.NewReader(data))
+	var eventDecoder streaming.Decoder
+	if contentType == protobufWatchContentType {
+		eventDecoder = streaming.NewDecoder(protobuf.LengthDelimitedFramer.NewFrameReader(stream), protobufRawSerializer)
+	} else {
+		eventDecoder = streaming.NewDecoder(jsonserializer.Framer.NewFrameReader(stream),
+			jsonserializer.NewSerializerWithOptions(jsonserializer.DefaultMetaFactory, scheme.Scheme, scheme.Scheme, jsonserializer.SerializerOptions{}))
+	}
+	eventType, object, err := restclientwatch.NewDecoder(eventDecoder, embeddedDecoder).Decode()
+	if err != nil {
+		t.Fatalf("unable to decode %s watch event: %v", contentType, err)
+	}
+	return eventType, object
+}
+
+func TestEncodedWatchEventRoundTrip(t *testing.T) {
+	// Objects sent by the simulation usually carry their kind, which the JSON decoder of client-go requires
+	typedPod := benchmarkPod(1)
+	typedPod.TypeMeta = metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"}
+	rawPod, err := json.Marshal(typedPod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		event        metav1.WatchEvent
+		contentTypes []string
+	}{
+		{"object", metav1.WatchEvent{Type: "MODIFIED", Object: runtime.RawExtension{Object: typedPod}}, []string{jsonWatchContentType, protobufWatchContentType}},
+		// Protobuf sets the kind of objects that do not carry it, JSON keeps them unchanged
+		{"object without kind", metav1.WatchEvent{Type: "ADDED", Object: runtime.RawExtension{Object: benchmarkPod(1)}}, []string{protobufWatchContentType}},
+		// Raw objects are decoded into an object created by newObject for protobuf
+		{"raw object", metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Raw: rawPod}}, []string{jsonWatchContentType, protobufWatchContentType}},
+	}
+	for _, test := range tests {
+		for _, contentType := range test.contentTypes {
+			t.Run(test.name+" "+contentType, func(t *testing.T) {
+				encoded := &encodedWatchEvent{event: test.event, newObject: listItemFactory[v1.PodList]()}
+				data, err := encoded.encode(contentType)
+				if err != nil {
+					t.Fatalf("encode returned error %v", err)
+				}
+				eventType, object := decodeWatchEvent(t, contentType, data, scheme.Codecs.UniversalDeserializer())
+				if string(eventType) != test.event.Type {
+					t.Fatalf("decoded event type %s, want %s", eventType, test.event.Type)
+				}
+				pod, ok := object.(*v1.Pod)
+				if !ok {
+					t.Fatalf("decoded %T, want *v1.Pod", object)
+				}
+				pod.TypeMeta = metav1.TypeMeta{}
+				want := benchmarkPod(1)
+				if !equality.Semantic.DeepEqual(pod, want) {
+					t.Fatalf("decoded pod differs from the sent one:\n%v\n%v", pod, want)
+				}
+			})
+		}
+	}
+	// Encoding protobuf must not set the kind of the object shared with the JSON encoding
+	untyped := &encodedWatchEvent{event: metav1.WatchEvent{Type: "ADDED", Object: runtime.RawExtension{Object: benchmarkPod(1)}}}
+	if _, err := untyped.encode(protobufWatchContentType); err != nil {
+		t.Fatal(err)
+	}
+	data, err := untyped.encode(jsonWatchContentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(`"kind"`)) {
+		t.Fatalf("JSON encoding of an object without kind contains a kind: %s", data)
+	}
+}
+
+// Cluster API kinds are not registered in scheme.Scheme, they are only served as JSON
+func TestEncodedWatchEventClusterApiKind(t *testing.T) {
+	machine := &cluster.Machine{
+		TypeMeta:   metav1.TypeMeta{Kind: "Machine", APIVersion: cluster.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "default"},
+	}
+	encoded := &encodedWatchEvent{event: metav1.WatchEvent{Type: "ADDED", Object: runtime.RawExtension{Object: machine}}}
+	if _, err := encoded.encode(protobufWatchContentType); err == nil {
+		t.Fatal("protobuf encoding of a kind missing from the scheme did not fail")
+	}
+	if contentType := negotiateWatchContentType[cluster.MachineList](httptest.NewRequest(http.MethodGet, "/", nil)); contentType != jsonWatchContentType {
+		t.Fatalf("negotiated %s for machines, want JSON", contentType)
+	}
+
+	data, err := encoded.encode(jsonWatchContentType)
+	if err != nil {
+		t.Fatalf("encode returned error %v", err)
+	}
+	clusterScheme := runtime.NewScheme()
+	if err := cluster.AddToScheme(clusterScheme); err != nil {
+		t.Fatal(err)
+	}
+	eventType, object := decodeWatchEvent(t, jsonWatchContentType, data, serializer.NewCodecFactory(clusterScheme).UniversalDeserializer())
+	if decoded, ok := object.(*cluster.Machine); eventType != watch.Added || !ok || decoded.Name != "machine-1" {
+		t.Fatalf("decoded %s %#v", eventType, object)
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"go-kube/internal/broadcast"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "adapter"
+
+// Registry of all metrics of the adapter, served at /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// Handled HTTP requests by virtual cluster, route template, verb and status code
+	Requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of handled HTTP requests by cluster, route, verb and status code.",
+	}, []string{"cluster", "route", "verb", "code"})
+
+	// Latency of HTTP requests by virtual cluster, route template and verb, long-running requests are not observed
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by cluster, route and verb, excluding watches and streams.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 16),
+	}, []string{"cluster", "route", "verb"})
+
+	// Open watch connections by virtual cluster and resource
+	ActiveWatches = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_watches",
+		Help:      "Number of open watch connections by cluster and resource.",
+	}, []string{"cluster", "resource"})
+
+	// Time between receiving pods to be placed and answering the simulation, by virtual cluster
+	SchedulingRoundDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduling_round_duration_seconds",
+		Help:      "Time from receiving pods to be placed until all of them are bound or failed, by cluster.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 18),
+	}, []string{"cluster"})
+
+	// Bound and failed pods of each scheduling round by virtual cluster
+	SchedulingRoundPods = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scheduling_round_pods",
+		Help:      "Number of pods bound or failed per scheduling round by cluster.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
+	}, []string{"cluster", "result"})
+
+	// Scale operations of the cluster-autoscaler on machine sets by virtual cluster
+	MachineSetScaleOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "machineset_scale_operations_total",
+		Help:      "Number of scale operations on machine sets by cluster, machine set and direction.",
+	}, []string{"cluster", "machineset", "direction"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		Requests,
+		RequestDuration,
+		ActiveWatches,
+		SchedulingRoundDuration,
+		SchedulingRoundPods,
+		MachineSetScaleOperations,
+		broadcasterCollector{},
+	)
+}
+
+var (
+	broadcasterSubscribers = prometheus.NewDesc(prometheus.BuildFQName(namespace, "broadcaster", "subscribers"),
+		"Number of subscribers of a broadcaster.", []string{"cluster", "broadcaster"}, nil)
+	broadcasterMaxQueueDepth = prometheus.NewDesc(prometheus.BuildFQName(namespace, "broadcaster", "max_queue_depth"),
+		"Number of messages queued for the slowest subscriber of a broadcaster.", []string{"cluster", "broadcaster"}, nil)
+	broadcasterSourceDepth = prometheus.NewDesc(prometheus.BuildFQName(namespace, "broadcaster", "source_depth"),
+		"Number of messages waiting to be published by a broadcaster.", []string{"cluster", "broadcaster"}, nil)
+	broadcasterPublished = prometheus.NewDesc(prometheus.BuildFQName(namespace, "broadcaster", "published_total"),
+		"Number of messages published by a broadcaster.", []string{"cluster", "broadcaster"}, nil)
+	broadcasterDropped = prometheus.NewDesc(prometheus.BuildFQName(namespace, "broadcaster", "dropped_subscribers_total"),
+		"Number of subscribers dropped by a broadcaster because their queue was full.", []string{"cluster", "broadcaster"}, nil)
+)
+
+// Reads the stats of the running broadcasters on every scrape
+type broadcasterCollector struct{}
+
+func (broadcasterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- broadcasterSubscribers
+	ch <- broadcasterMaxQueueDepth
+	ch <- broadcasterSourceDepth
+	ch <- broadcasterPublished
+	ch <- broadcasterDropped
+}
+
+func (broadcasterCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range broadcast.AllStats() {
+		ch <- prometheus.MustNewConstMetric(broadcasterSubscribers, prometheus.GaugeValue, float64(stats.Subscribers), stats.Cluster, stats.Name)
+		ch <- prometheus.MustNewConstMetric(broadcasterMaxQueueDepth, prometheus.GaugeValue, float64(stats.MaxQueueDepth), stats.Cluster, stats.Name)
+		ch <- prometheus.MustNewConstMetric(broadcasterSourceDepth, prometheus.GaugeValue, float64(stats.SourceDepth), stats.Cluster, stats.Name)
+		ch <- prometheus.MustNewConstMetric(broadcasterPublished, prometheus.CounterValue, float64(stats.Published), stats.Cluster, stats.Name)
+		ch <- prometheus.MustNewConstMetric(broadcasterDropped, prometheus.CounterValue, float64(stats.DroppedSubscribers), stats.Cluster, stats.Name)
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Returns a middleware counting the requests of a virtual cluster and observing their latency.
+// They are labelled with the route template without the path prefix of the cluster,
+// so that object names and cluster ids do not end up in the route label.
+func Middleware(cluster string, pathPrefix string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return instrumentedHandler(cluster, pathPrefix, next)
+	}
+}
+
+func instrumentedHandler(cluster string, pathPrefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = strings.TrimPrefix(template, pathPrefix)
+			}
+		}
+		verb := Verb(r)
+		longRunning := verb == "WATCH" || r.Header.Get("Upgrade") != "" || r.Header.Get("Accept") == "text/event-stream"
+
+		start := time.Now()
+		recorder := NewStatusRecorder(w, nil)
+		next.ServeHTTP(recorder, r)
+
+		Requests.WithLabelValues(cluster, route, verb, strconv.Itoa(recorder.Status())).Inc()
+		if !longRunning {
+			RequestDuration.WithLabelValues(cluster, route, verb).Observe(time.Since(start).Seconds())
+		}
+	})
+}
+
+// Returns the method of the request, or WATCH for watches
+func Verb(r *http.Request) string {
+	if r.URL.Query().Get("watch") != "" {
+		return "WATCH"
+	}
+	return r.Method
+}
+
+// Remembers the status code, watches and streams need the flusher and hijacker of the wrapped writer
+type StatusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	// Called once as soon as the status is known, may be nil
+	onStatus func(status int)
+}
+
+// Wraps w, onStatus is called with the status before anything is written to w
+func NewStatusRecorder(w http.ResponseWriter, onStatus func(status int)) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, status: http.StatusOK, onStatus: onStatus}
+}
+
+// Returns the status of the response, 200 if the handler has not written anything
+func (r *StatusRecorder) Status() int {
+	return r.status
+}
+
+// Whether the handler has written the status or a part of the body
+func (r *StatusRecorder) WroteHeader() bool {
+	return r.wroteHeader
+}
+
+func (r *StatusRecorder) setStatus(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+	if r.onStatus != nil {
+		r.onStatus(status)
+	}
+}
+
+func (r *StatusRecorder) WriteHeader(status int) {
+	r.setStatus(status)
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *StatusRecorder) Write(data []byte) (int, error) {
+	r.setStatus(http.StatusOK)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *StatusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	r.setStatus(http.StatusSwitchingProtocols)
+	return hijacker.Hijack()
+}
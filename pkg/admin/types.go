@@ -0,0 +1,114 @@
+package admin
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Response of the adapter to a reset
+type ResetResponse struct {
+	// Number of removed objects per kind, DELETED events were sent for all of them except events
+	Deleted map[string]int `json:"deleted"`
+}
+
+// Response of the adapter to loading manifests
+type BootstrapResponse struct {
+	// Number of loaded objects per kind, objects that replaced stored ones are included
+	Loaded map[string]int `json:"loaded"`
+}
+
+// Request to create a virtual cluster
+type ClusterRequest struct {
+	// Id of the cluster, a DNS label. Its API is served below /clusters/{id}.
+	Id string `json:"id"`
+	// Optional address of an additional listener serving the API of the cluster at the root paths, e.g. ":8001".
+	// It has to be one of the clusterListenAddresses of the config file.
+	ListenAddress string `json:"listenAddress,omitempty"`
+	// Optional file the results of the rounds of the cluster are appended to, relative to the resultsDir of the config file
+	ResultsFile string `json:"resultsFile,omitempty"`
+	// Format of the results file, csv or jsonl (default)
+	ResultsFormat string `json:"resultsFormat,omitempty"`
+}
+
+// Virtual cluster hosted by the adapter
+type ClusterInfo struct {
+	Id string `json:"id"`
+	// Path prefix of the API of the cluster
+	Path          string    `json:"path"`
+	ListenAddress string    `json:"listenAddress,omitempty"`
+	ResultsFile   string    `json:"resultsFile,omitempty"`
+	Created       time.Time `json:"created"`
+}
+
+// How the adapter serves a route of the Kubernetes API
+const (
+	RouteSupported = "supported"
+	// Served with an empty list or a watch without events
+	RouteUnsupported = "unsupported"
+	// No route exists, the request was answered with 404 or 405
+	RouteUnknown = "unknown"
+)
+
+// Requests to the API of a virtual cluster by component, verb and route
+type ApiUsageReport struct {
+	// Start of the recording, the adapter start or the last clearing of the report
+	Since      time.Time           `json:"since"`
+	Components []ComponentApiUsage `json:"components"`
+	// Unsupported and unknown calls of all components, e.g. "GET /apis/batch/v1/jobs"
+	Unsupported []string `json:"unsupported"`
+	Unknown     []string `json:"unknown"`
+}
+
+// Requests of the component with one User-Agent
+type ComponentApiUsage struct {
+	// Program name at the start of the User-Agent, e.g. kube-scheduler
+	Name      string `json:"name"`
+	UserAgent string `json:"userAgent"`
+	Requests  int    `json:"requests"`
+	// Requests that were not recorded per call, because the component already made too many different calls
+	OtherRequests int            `json:"otherRequests,omitempty"`
+	Calls         []ApiCallUsage `json:"calls"`
+}
+
+// Requests with the same verb to one route
+type ApiCallUsage struct {
+	// Method of the request, or WATCH for watches
+	Verb string `json:"verb"`
+	// Route template, e.g. /api/v1/nodes/{nodeName}, or the request path for unknown routes
+	Path    string `json:"path"`
+	Support string `json:"support"`
+	Count   int    `json:"count"`
+	// Number of responses by status code
+	Statuses map[int]int `json:"statuses"`
+	LastSeen time.Time   `json:"lastSeen"`
+}
+
+// Faults injected into the Kubernetes API of a virtual cluster
+type FaultConfig struct {
+	// Seed of the random decisions, the same seed and sequence of requests result in the same faults
+	Seed int64 `json:"seed"`
+	// The first rule matching a request applies
+	Rules []FaultRule `json:"rules"`
+}
+
+// Faults of the requests to the matching routes
+type FaultRule struct {
+	// Route template, e.g. /api/v1/nodes/{nodeName}, a trailing * matches every route with the prefix.
+	// Empty matches all routes.
+	Route string `json:"route,omitempty"`
+	// Methods of the requests, WATCH for watches, empty matches all
+	Verbs []string `json:"verbs,omitempty"`
+	// Delay before the request is handled, e.g. "200ms"
+	Latency metav1.Duration `json:"latency,omitempty"`
+	// Random additional delay up to the duration
+	LatencyJitter metav1.Duration `json:"latencyJitter,omitempty"`
+	// Fraction of the requests answered with ErrorStatus, between 0 and 1
+	ErrorRate float64 `json:"errorRate,omitempty"`
+	// Status code of the injected errors, 500 by default
+	ErrorStatus int `json:"errorStatus,omitempty"`
+	// Fraction of the PUT and PATCH requests answered with 409 Conflict, between 0 and 1
+	ConflictRate float64 `json:"conflictRate,omitempty"`
+	// Watches are closed after sending this many events, 0 keeps them open
+	WatchDisconnectAfter int `json:"watchDisconnectAfter,omitempty"`
+}
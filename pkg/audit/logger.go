@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// Annotation of the events naming the virtual cluster the request was made to
+const ClusterAnnotation = "misim-k8s-adapter/cluster"
+
+// Appends audit events as JSON lines to a log file. Events are written immediately,
+// so the file can be followed while the experiment runs.
+type Logger struct {
+	policy Policy
+
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	closed  bool
+}
+
+// Opens the log file for appending, it is created if it does not exist
+func Open(path string, policy Policy) (*Logger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Logger{policy: policy, file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+func (l *Logger) Policy() Policy {
+	return l.policy
+}
+
+// Returns the event of the request at the RequestReceived stage, its level is set by the policy
+func (l *Logger) NewEvent(r *http.Request, attributes RequestAttributes, level Level, cluster string) Event {
+	now := metav1.NewMicroTime(time.Now())
+	return Event{
+		TypeMeta:                 metav1.TypeMeta{Kind: "Event", APIVersion: APIVersion},
+		Level:                    level,
+		AuditID:                  string(uuid.NewUUID()),
+		Stage:                    StageRequestReceived,
+		RequestURI:               r.URL.RequestURI(),
+		Verb:                     attributes.Verb,
+		User:                     anonymousUser,
+		SourceIPs:                sourceIPs(r),
+		UserAgent:                r.UserAgent(),
+		ObjectRef:                attributes.ObjectRef,
+		RequestReceivedTimestamp: now,
+		StageTimestamp:           now,
+		Annotations:              map[string]string{ClusterAnnotation: cluster},
+	}
+}
+
+// Appends the event to the log file
+func (l *Logger) Log(event Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Requests cut off at shutdown may still finish after the file was closed
+	if l.closed {
+		return errors.New("the audit log is closed")
+	}
+	return l.encoder.Encode(event)
+}
+
+// Writes the file to disk and closes it
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	syncErr := l.file.Sync()
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	return syncErr
+}
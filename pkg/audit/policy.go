@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// Logs every request at level Metadata, used without policy file
+func DefaultPolicy() Policy {
+	return Policy{Rules: []PolicyRule{{Level: LevelMetadata}}}
+}
+
+// Reads an audit policy file in the audit.k8s.io/v1 format, as used by the Kubernetes API server
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+	var policy Policy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return Policy{}, fmt.Errorf("unable to parse audit policy %s: %w", path, err)
+	}
+	if errs := policy.Validate(); len(errs) > 0 {
+		return Policy{}, fmt.Errorf("invalid audit policy %s: %w", path, errs.ToAggregate())
+	}
+	return policy, nil
+}
+
+func (p Policy) Validate() field.ErrorList {
+	var errs field.ErrorList
+	if p.APIVersion != APIVersion {
+		errs = append(errs, field.NotSupported(field.NewPath("apiVersion"), p.APIVersion, []string{APIVersion}))
+	}
+	if p.Kind != "Policy" {
+		errs = append(errs, field.NotSupported(field.NewPath("kind"), p.Kind, []string{"Policy"}))
+	}
+	errs = append(errs, validateStages(field.NewPath("omitStages"), p.OmitStages)...)
+	for i, rule := range p.Rules {
+		path := field.NewPath("rules").Index(i)
+		if _, found := levelOrder[rule.Level]; !found {
+			errs = append(errs, field.NotSupported(path.Child("level"), rule.Level, []string{string(LevelNone), string(LevelMetadata), string(LevelRequest), string(LevelRequestResponse)}))
+		}
+		if len(rule.NonResourceURLs) > 0 && (len(rule.Resources) > 0 || len(rule.Namespaces) > 0) {
+			errs = append(errs, field.Invalid(path.Child("nonResourceURLs"), rule.NonResourceURLs, "rules cannot apply to both regular resources and non-resource URLs"))
+		}
+		errs = append(errs, validateStages(path.Child("omitStages"), rule.OmitStages)...)
+	}
+	return errs
+}
+
+func validateStages(path *field.Path, omitted []Stage) field.ErrorList {
+	var errs field.ErrorList
+	valid := make([]string, len(stages))
+	for i, stage := range stages {
+		valid[i] = string(stage)
+	}
+	for i, stage := range omitted {
+		if !containsString(valid, string(stage)) {
+			errs = append(errs, field.NotSupported(path.Index(i), stage, valid))
+		}
+	}
+	return errs
+}
+
+// Returns the level of the request and the stages that are not logged
+func (p Policy) LevelFor(attributes RequestAttributes) (Level, []Stage) {
+	for _, rule := range p.Rules {
+		if rule.matches(attributes) {
+			return rule.Level, append(append([]Stage{}, p.OmitStages...), rule.OmitStages...)
+		}
+	}
+	return LevelNone, nil
+}
+
+func (rule PolicyRule) matches(attributes RequestAttributes) bool {
+	if len(rule.Users) > 0 && !containsString(rule.Users, anonymousUser.Username) {
+		return false
+	}
+	if len(rule.UserGroups) > 0 && !containsAny(rule.UserGroups, anonymousUser.Groups) {
+		return false
+	}
+	if len(rule.Verbs) > 0 && !containsString(rule.Verbs, attributes.Verb) {
+		return false
+	}
+	if len(rule.Resources) > 0 || len(rule.Namespaces) > 0 {
+		return attributes.ObjectRef != nil && rule.matchesResource(*attributes.ObjectRef)
+	}
+	if len(rule.NonResourceURLs) > 0 {
+		return attributes.ObjectRef == nil && matchesURL(rule.NonResourceURLs, attributes.Path)
+	}
+	return true
+}
+
+func (rule PolicyRule) matchesResource(ref ObjectReference) bool {
+	if len(rule.Namespaces) > 0 && !containsString(rule.Namespaces, ref.Namespace) {
+		return false
+	}
+	if len(rule.Resources) == 0 {
+		return true
+	}
+	for _, groupResources := range rule.Resources {
+		if groupResources.Group != ref.APIGroup {
+			continue
+		}
+		if len(groupResources.ResourceNames) > 0 && !containsString(groupResources.ResourceNames, ref.Name) {
+			continue
+		}
+		if len(groupResources.Resources) == 0 {
+			return true
+		}
+		for _, resource := range groupResources.Resources {
+			if matchesResourceName(resource, ref) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Matches e.g. "pods", "pods/status", "pods/*", "*/status" and "*"
+func matchesResourceName(pattern string, ref ObjectReference) bool {
+	if pattern == "*" {
+		return true
+	}
+	if ref.Subresource == "" {
+		return pattern == ref.Resource
+	}
+	resource, subresource, _ := strings.Cut(pattern, "/")
+	return (resource == "*" || resource == ref.Resource) && (subresource == "*" || subresource == ref.Subresource)
+}
+
+func matchesURL(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if prefix, found := strings.CutSuffix(pattern, "*"); found {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if pattern == path {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, candidate := range candidates {
+		if containsString(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
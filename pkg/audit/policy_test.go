@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchesResourceName(t *testing.T) {
+	pods := ObjectReference{Resource: "pods"}
+	podStatus := ObjectReference{Resource: "pods", Subresource: "status"}
+	nodeStatus := ObjectReference{Resource: "nodes", Subresource: "status"}
+	tests := []struct {
+		pattern string
+		ref     ObjectReference
+		want    bool
+	}{
+		{"*", pods, true},
+		{"*", podStatus, true},
+		{"pods", pods, true},
+		{"pods", podStatus, false},
+		{"nodes", pods, false},
+		{"pods/status", podStatus, true},
+		{"pods/status", pods, false},
+		{"pods/binding", podStatus, false},
+		{"pods/*", podStatus, true},
+		// pods/* only matches subresources of pods
+		{"pods/*", pods, false},
+		{"pods/*", nodeStatus, false},
+		{"*/status", podStatus, true},
+		{"*/status", nodeStatus, true},
+		{"*/status", pods, false},
+		{"*/status", ObjectReference{Resource: "pods", Subresource: "binding"}, false},
+	}
+	for _, test := range tests {
+		if got := matchesResourceName(test.pattern, test.ref); got != test.want {
+			t.Errorf("matchesResourceName(%q, %+v) = %t, want %t", test.pattern, test.ref, got, test.want)
+		}
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	policy := Policy{
+		OmitStages: []Stage{StageRequestReceived},
+		Rules: []PolicyRule{
+			{Level: LevelNone, NonResourceURLs: []string{"/version", "/apis*"}},
+			{Level: LevelNone, Verbs: []string{"watch"}},
+			{Level: LevelRequestResponse, Resources: []GroupResources{{Resources: []string{"pods/binding"}}}, OmitStages: []Stage{StageResponseStarted}},
+			{Level: LevelRequest, Resources: []GroupResources{{Resources: []string{"*/status"}}}},
+			{Level: LevelMetadata, Resources: []GroupResources{{Group: "apps"}}},
+			{Level: LevelMetadata, Resources: []GroupResources{{Resources: []string{"nodes"}, ResourceNames: []string{"node-1"}}}},
+			{Level: LevelRequest, Namespaces: []string{""}},
+			{Level: LevelMetadata, Namespaces: []string{"kube-system"}, Users: []string{"admin"}},
+			{Level: LevelMetadata, Namespaces: []string{"kube-system"}, UserGroups: []string{"system:unauthenticated"}},
+		},
+	}
+	resource := func(verb string, ref ObjectReference) RequestAttributes {
+		return RequestAttributes{Verb: verb, ObjectRef: &ref}
+	}
+	tests := []struct {
+		name       string
+		attributes RequestAttributes
+		wantLevel  Level
+		wantOmit   []Stage
+	}{
+		{"non-resource url", RequestAttributes{Verb: "get", Path: "/version"}, LevelNone, []Stage{StageRequestReceived}},
+		{"non-resource url prefix", RequestAttributes{Verb: "get", Path: "/apis/apps/v1"}, LevelNone, []Stage{StageRequestReceived}},
+		// Non-resource requests do not match rules with namespaces
+		{"unmatched non-resource url", RequestAttributes{Verb: "get", Path: "/api"}, LevelNone, nil},
+		{"verb", resource("watch", ObjectReference{Resource: "pods", Namespace: "x"}), LevelNone, []Stage{StageRequestReceived}},
+		{"subresource with rule stages", resource("create", ObjectReference{Resource: "pods", Namespace: "x", Name: "y", Subresource: "binding"}),
+			LevelRequestResponse, []Stage{StageRequestReceived, StageResponseStarted}},
+		{"subresource wildcard", resource("update", ObjectReference{Resource: "nodes", Name: "n", Subresource: "status"}), LevelRequest, []Stage{StageRequestReceived}},
+		{"group", resource("list", ObjectReference{Resource: "deployments", Namespace: "x", APIGroup: "apps"}), LevelMetadata, []Stage{StageRequestReceived}},
+		{"resource name", resource("get", ObjectReference{Resource: "nodes", Name: "node-1"}), LevelMetadata, []Stage{StageRequestReceived}},
+		{"cluster-scoped", resource("get", ObjectReference{Resource: "nodes", Name: "node-2"}), LevelRequest, []Stage{StageRequestReceived}},
+		// Every request is made by the anonymous user
+		{"user group", resource("list", ObjectReference{Resource: "pods", Namespace: "kube-system"}), LevelMetadata, []Stage{StageRequestReceived}},
+		{"no rule", resource("list", ObjectReference{Resource: "pods", Namespace: "default"}), LevelNone, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			level, omit := policy.LevelFor(test.attributes)
+			if level != test.wantLevel || !reflect.DeepEqual(omit, test.wantOmit) {
+				t.Fatalf("LevelFor returned %s %v, want %s %v", level, omit, test.wantLevel, test.wantOmit)
+			}
+		})
+	}
+}
+
+func TestDefaultPolicyLogsEveryRequest(t *testing.T) {
+	for _, attributes := range []RequestAttributes{
+		{Verb: "get", Path: "/version"},
+		{Verb: "create", ObjectRef: &ObjectReference{Resource: "pods", Namespace: "x", Name: "y", Subresource: "binding"}},
+	} {
+		if level, _ := DefaultPolicy().LevelFor(attributes); level != LevelMetadata {
+			t.Fatalf("default policy logs %+v at level %s", attributes, level)
+		}
+	}
+}
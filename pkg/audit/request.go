@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// What a request to the Kubernetes API accesses, like the RequestInfo of the Kubernetes API server
+type RequestAttributes struct {
+	// Kubernetes verb, e.g. list or watch, or the lowercase method for non-resource requests
+	Verb string
+	// Nil for non-resource requests like discovery or /version
+	ObjectRef *ObjectReference
+	// Path of the request within its virtual cluster
+	Path string
+}
+
+// Subresources of namespace objects, /namespaces/{name}/{subresource}
+var namespaceSubresources = map[string]bool{"status": true, "finalize": true}
+
+// Returns the attributes of the request to path, the path of the request without the prefix of its virtual cluster
+func NewRequestAttributes(r *http.Request, path string) RequestAttributes {
+	attributes := RequestAttributes{Verb: strings.ToLower(r.Method), Path: path}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var ref ObjectReference
+	switch {
+	case len(segments) >= 3 && segments[0] == "api":
+		ref.APIVersion, segments = segments[1], segments[2:]
+	case len(segments) >= 4 && segments[0] == "apis":
+		ref.APIGroup, ref.APIVersion, segments = segments[1], segments[2], segments[3:]
+	default:
+		// Discovery and other non-resource URLs
+		return attributes
+	}
+	// /namespaces/{name} and its status and finalize subresources address the namespace object
+	// itself, which is cluster-scoped
+	if segments[0] == "namespaces" && len(segments) >= 3 && !namespaceSubresources[segments[2]] {
+		ref.Namespace = segments[1]
+		segments = segments[2:]
+	}
+	ref.Resource = segments[0]
+	if len(segments) >= 2 {
+		ref.Name = segments[1]
+	}
+	if len(segments) >= 3 {
+		ref.Subresource = strings.Join(segments[2:], "/")
+	}
+	attributes.ObjectRef = &ref
+
+	watch := r.URL.Query().Get("watch")
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if watch == "true" || watch == "1" {
+			attributes.Verb = "watch"
+		} else if ref.Name == "" {
+			attributes.Verb = "list"
+		} else {
+			attributes.Verb = "get"
+		}
+	case http.MethodPost:
+		attributes.Verb = "create"
+	case http.MethodPut:
+		attributes.Verb = "update"
+	case http.MethodPatch:
+		attributes.Verb = "patch"
+	case http.MethodDelete:
+		if ref.Name == "" {
+			attributes.Verb = "deletecollection"
+		} else {
+			attributes.Verb = "delete"
+		}
+	}
+	return attributes
+}
+
+// Returns the addresses of the client, the ones of the X-Forwarded-For header first
+func sourceIPs(r *http.Request) []string {
+	var ips []string
+	for _, forwarded := range strings.Split(r.Header.Get("X-Forwarded-For"), ",") {
+		if ip := strings.TrimSpace(forwarded); ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ips = append(ips, host)
+	}
+	return ips
+}
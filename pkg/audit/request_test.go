@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestNewRequestAttributes(t *testing.T) {
+	tests := []struct {
+		method   string
+		url      string
+		wantVerb string
+		// Nil for non-resource requests
+		wantRef *ObjectReference
+	}{
+		{method: http.MethodGet, url: "/api/v1/namespaces/x", wantVerb: "get",
+			wantRef: &ObjectReference{Resource: "namespaces", Name: "x", APIVersion: "v1"}},
+		{method: http.MethodPut, url: "/api/v1/namespaces/x/status", wantVerb: "update",
+			wantRef: &ObjectReference{Resource: "namespaces", Name: "x", APIVersion: "v1", Subresource: "status"}},
+		{method: http.MethodPut, url: "/api/v1/namespaces/x/finalize", wantVerb: "update",
+			wantRef: &ObjectReference{Resource: "namespaces", Name: "x", APIVersion: "v1", Subresource: "finalize"}},
+		{method: http.MethodGet, url: "/api/v1/namespaces", wantVerb: "list",
+			wantRef: &ObjectReference{Resource: "namespaces", APIVersion: "v1"}},
+		{method: http.MethodGet, url: "/api/v1/namespaces/x/pods", wantVerb: "list",
+			wantRef: &ObjectReference{Resource: "pods", Namespace: "x", APIVersion: "v1"}},
+		{method: http.MethodGet, url: "/api/v1/pods?watch=true", wantVerb: "watch",
+			wantRef: &ObjectReference{Resource: "pods", APIVersion: "v1"}},
+		{method: http.MethodGet, url: "/api/v1/namespaces/x/pods/y?watch=1", wantVerb: "watch",
+			wantRef: &ObjectReference{Resource: "pods", Namespace: "x", Name: "y", APIVersion: "v1"}},
+		{method: http.MethodPost, url: "/api/v1/namespaces/x/pods/y/binding", wantVerb: "create",
+			wantRef: &ObjectReference{Resource: "pods", Namespace: "x", Name: "y", APIVersion: "v1", Subresource: "binding"}},
+		{method: http.MethodPatch, url: "/api/v1/nodes/n/status", wantVerb: "patch",
+			wantRef: &ObjectReference{Resource: "nodes", Name: "n", APIVersion: "v1", Subresource: "status"}},
+		{method: http.MethodDelete, url: "/api/v1/namespaces/x/pods/y", wantVerb: "delete",
+			wantRef: &ObjectReference{Resource: "pods", Namespace: "x", Name: "y", APIVersion: "v1"}},
+		{method: http.MethodDelete, url: "/api/v1/namespaces/x/pods", wantVerb: "deletecollection",
+			wantRef: &ObjectReference{Resource: "pods", Namespace: "x", APIVersion: "v1"}},
+		{method: http.MethodGet, url: "/apis/apps/v1/namespaces/x/deployments", wantVerb: "list",
+			wantRef: &ObjectReference{Resource: "deployments", Namespace: "x", APIGroup: "apps", APIVersion: "v1"}},
+		{method: http.MethodPut, url: "/apis/apps/v1/namespaces/x/deployments/d/scale", wantVerb: "update",
+			wantRef: &ObjectReference{Resource: "deployments", Namespace: "x", Name: "d", APIGroup: "apps", APIVersion: "v1", Subresource: "scale"}},
+		{method: http.MethodGet, url: "/apis/cluster.x-k8s.io/v1beta1/machines", wantVerb: "list",
+			wantRef: &ObjectReference{Resource: "machines", APIGroup: "cluster.x-k8s.io", APIVersion: "v1beta1"}},
+		// Discovery and other non-resource URLs keep the lowercase method as verb
+		{method: http.MethodGet, url: "/api", wantVerb: "get"},
+		{method: http.MethodGet, url: "/api/v1", wantVerb: "get"},
+		{method: http.MethodGet, url: "/apis", wantVerb: "get"},
+		{method: http.MethodGet, url: "/apis/apps", wantVerb: "get"},
+		{method: http.MethodGet, url: "/apis/apps/v1", wantVerb: "get"},
+		{method: http.MethodGet, url: "/version", wantVerb: "get"},
+		{method: http.MethodPost, url: "/misim/updatePods", wantVerb: "post"},
+	}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.url, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.url, nil)
+			attributes := NewRequestAttributes(r, r.URL.Path)
+			if attributes.Verb != test.wantVerb {
+				t.Fatalf("NewRequestAttributes returned verb %s, want %s", attributes.Verb, test.wantVerb)
+			}
+			if !reflect.DeepEqual(attributes.ObjectRef, test.wantRef) {
+				t.Fatalf("NewRequestAttributes returned object %+v, want %+v", attributes.ObjectRef, test.wantRef)
+			}
+			if attributes.Path != r.URL.Path {
+				t.Fatalf("NewRequestAttributes returned path %s, want %s", attributes.Path, r.URL.Path)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The types mirror audit.k8s.io/v1, so that the log and the policy file can be used
+// with the tooling for Kubernetes clusters. See the Kubernetes API reference for details.
+
+const (
+	Group      = "audit.k8s.io"
+	APIVersion = Group + "/v1"
+)
+
+// How much of a request is logged
+type Level string
+
+const (
+	// The request is not logged
+	LevelNone Level = "None"
+	// Request metadata like user agent, verb and resource, without request and response bodies
+	LevelMetadata Level = "Metadata"
+	// Metadata and the request body
+	LevelRequest Level = "Request"
+	// Metadata, request body and response body
+	LevelRequestResponse Level = "RequestResponse"
+)
+
+// Whether the level includes the passed level
+func (l Level) Includes(other Level) bool {
+	return levelOrder[l] >= levelOrder[other]
+}
+
+var levelOrder = map[Level]int{LevelNone: 0, LevelMetadata: 1, LevelRequest: 2, LevelRequestResponse: 3}
+
+// Stage of the request handling at which an event is logged
+type Stage string
+
+const (
+	StageRequestReceived Stage = "RequestReceived"
+	// Only logged for long-running requests like watches, when the response headers were sent
+	StageResponseStarted  Stage = "ResponseStarted"
+	StageResponseComplete Stage = "ResponseComplete"
+)
+
+var stages = []Stage{StageRequestReceived, StageResponseStarted, StageResponseComplete}
+
+type Event struct {
+	metav1.TypeMeta `json:",inline"`
+	Level           Level            `json:"level"`
+	AuditID         string           `json:"auditID"`
+	Stage           Stage            `json:"stage"`
+	RequestURI      string           `json:"requestURI"`
+	Verb            string           `json:"verb"`
+	User            UserInfo         `json:"user"`
+	SourceIPs       []string         `json:"sourceIPs,omitempty"`
+	UserAgent       string           `json:"userAgent,omitempty"`
+	ObjectRef       *ObjectReference `json:"objectRef,omitempty"`
+	ResponseStatus  *metav1.Status   `json:"responseStatus,omitempty"`
+	// Bodies of JSON requests and responses, depending on the level
+	RequestObject            json.RawMessage   `json:"requestObject,omitempty"`
+	ResponseObject           json.RawMessage   `json:"responseObject,omitempty"`
+	RequestReceivedTimestamp metav1.MicroTime  `json:"requestReceivedTimestamp"`
+	StageTimestamp           metav1.MicroTime  `json:"stageTimestamp"`
+	Annotations              map[string]string `json:"annotations,omitempty"`
+}
+
+// The adapter does not authenticate, all requests are made by the anonymous user
+type UserInfo struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+var anonymousUser = UserInfo{Username: "system:anonymous", Groups: []string{"system:unauthenticated"}}
+
+type ObjectReference struct {
+	Resource    string `json:"resource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	APIGroup    string `json:"apiGroup,omitempty"`
+	APIVersion  string `json:"apiVersion,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+}
+
+// Decides the level of every request, see LoadPolicy
+type Policy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// The first rule matching a request sets its level, requests matching no rule are not logged
+	Rules []PolicyRule `json:"rules"`
+	// Stages that are not logged for any request
+	OmitStages []Stage `json:"omitStages,omitempty"`
+	// Accepted for compatibility, the objects of the adapter have no managed fields
+	OmitManagedFields bool `json:"omitManagedFields,omitempty"`
+}
+
+type PolicyRule struct {
+	Level Level `json:"level"`
+	// Empty lists match every request
+	Users      []string `json:"users,omitempty"`
+	UserGroups []string `json:"userGroups,omitempty"`
+	Verbs      []string `json:"verbs,omitempty"`
+	// Rules with resources or namespaces only match resource requests
+	Resources []GroupResources `json:"resources,omitempty"`
+	// "" matches cluster-scoped resources
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Rules with non-resource URLs only match requests that are no resource requests, e.g. /version.
+	// A trailing * matches every URL with the prefix.
+	NonResourceURLs   []string `json:"nonResourceURLs,omitempty"`
+	OmitStages        []Stage  `json:"omitStages,omitempty"`
+	OmitManagedFields *bool    `json:"omitManagedFields,omitempty"`
+}
+
+type GroupResources struct {
+	// "" is the core group
+	Group string `json:"group,omitempty"`
+	// e.g. "pods", "pods/status", "pods/*" or "*/status". Empty matches all resources of the group.
+	Resources     []string `json:"resources,omitempty"`
+	ResourceNames []string `json:"resourceNames,omitempty"`
+}
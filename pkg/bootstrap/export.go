@@ -0,0 +1,137 @@
+package bootstrap
+
+import (
+	"fmt"
+	"io"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	cluster "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// Namespaces that every Kubernetes cluster has, they are not exported
+var builtinNamespaces = map[string]bool{"default": true, "kube-system": true, "kube-public": true, "kube-node-lease": true}
+
+// Taints the cluster autoscaler sets on nodes it is about to remove
+var autoscalerTaints = map[string]bool{"ToBeDeletedByClusterAutoscaler": true, "DeletionCandidateOfClusterAutoscaler": true}
+
+// Returns the namespace with its name, labels and annotations only, false for built-in namespaces
+func ExportNamespace(namespace v1.Namespace) (v1.Namespace, bool) {
+	if builtinNamespaces[namespace.Name] {
+		return v1.Namespace{}, false
+	}
+	return v1.Namespace{ObjectMeta: exportMeta(namespace.ObjectMeta)}, true
+}
+
+// Returns the node without runtime state. Capacity and allocatable resources describe the
+// node rather than its state, so they are kept in the status. The status is a subresource that
+// a plain kubectl apply does not set, it has to be applied separately with --subresource=status.
+func ExportNode(node v1.Node) v1.Node {
+	exported := v1.Node{ObjectMeta: exportMeta(node.ObjectMeta), Spec: *node.Spec.DeepCopy()}
+	exported.Spec.Taints = nil
+	for _, taint := range node.Spec.Taints {
+		if !autoscalerTaints[taint.Key] {
+			exported.Spec.Taints = append(exported.Spec.Taints, taint)
+		}
+	}
+	exported.Status.Capacity = node.Status.Capacity.DeepCopy()
+	exported.Status.Allocatable = node.Status.Allocatable.DeepCopy()
+	return exported
+}
+
+func ExportMachineSet(machineSet cluster.MachineSet) cluster.MachineSet {
+	return cluster.MachineSet{ObjectMeta: exportMeta(machineSet.ObjectMeta), Spec: *machineSet.Spec.DeepCopy()}
+}
+
+func ExportMachine(machine cluster.Machine) cluster.Machine {
+	return cluster.Machine{ObjectMeta: exportMeta(machine.ObjectMeta), Spec: *machine.Spec.DeepCopy()}
+}
+
+// Returns the pod pinned to the node it is bound to, false if it is not bound or has terminated
+func ExportPod(pod v1.Pod) (v1.Pod, bool) {
+	if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return v1.Pod{}, false
+	}
+	return v1.Pod{ObjectMeta: exportMeta(pod.ObjectMeta), Spec: *pod.Spec.DeepCopy()}, true
+}
+
+// Keeps the fields of the metadata a user sets. Owner references are dropped because
+// they refer to objects by uid, which differs in another cluster.
+func exportMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	exported := metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: make(map[string]string, len(meta.Annotations)),
+	}
+	for key, value := range meta.Annotations {
+		if key != "kubectl.kubernetes.io/last-applied-configuration" {
+			exported.Annotations[key] = value
+		}
+	}
+	if len(exported.Annotations) == 0 {
+		exported.Annotations = nil
+	}
+	return exported
+}
+
+// Writes the objects as YAML documents separated by "---", every document carries apiVersion and kind
+func WriteYAML(w io.Writer, objects []runtime.Object) error {
+	for i, object := range objects {
+		kinds, _, err := manifestScheme.ObjectKinds(object)
+		if err != nil {
+			return err
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			return err
+		}
+		content["apiVersion"], content["kind"] = kinds[0].GroupVersion().String(), kinds[0].Kind
+		// Left over from the zero values of the exported objects
+		if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+			delete(metadata, "creationTimestamp")
+		}
+		if status, ok := content["status"].(map[string]interface{}); ok && len(pruneEmpty(status)) == 0 {
+			delete(content, "status")
+		}
+		data, err := yaml.Marshal(content)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := fmt.Fprintln(w, "---"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes zero values from the map recursively, status structs have fields without omitempty
+func pruneEmpty(content map[string]interface{}) map[string]interface{} {
+	for key, value := range content {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = pruneEmpty(nested)
+		}
+		switch typed := value.(type) {
+		case nil, string, bool, int64, float64:
+			if typed == nil || typed == "" || typed == false || typed == int64(0) || typed == float64(0) {
+				delete(content, key)
+			}
+		case map[string]interface{}:
+			if len(typed) == 0 {
+				delete(content, key)
+			}
+		case []interface{}:
+			if len(typed) == 0 {
+				delete(content, key)
+			}
+		}
+	}
+	return content
+}
@@ -0,0 +1,154 @@
+package bootstrap
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	scheduling "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	cluster "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// Kinds that can be loaded from manifests
+var SupportedKinds = []string{"Namespace", "PriorityClass", "ConfigMap", "MachineSet", "Machine", "Node", "DaemonSet"}
+
+// Objects read from Kubernetes manifests, in the order they were read
+type Manifests struct {
+	Namespaces      []v1.Namespace
+	PriorityClasses []scheduling.PriorityClass
+	ConfigMaps      []v1.ConfigMap
+	MachineSets     []cluster.MachineSet
+	Machines        []cluster.Machine
+	Nodes           []v1.Node
+	DaemonSets      []apps.DaemonSet
+}
+
+var manifestScheme = runtime.NewScheme()
+
+// Rejects unknown fields, so that typos in manifests do not go unnoticed
+var manifestDecoder runtime.Decoder
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(manifestScheme))
+	utilruntime.Must(cluster.AddToScheme(manifestScheme))
+	manifestDecoder = serializer.NewCodecFactory(manifestScheme, serializer.EnableStrict).UniversalDeserializer()
+}
+
+// Reads the passed files and the .yaml, .yml and .json files below the passed directories
+func ReadFiles(paths []string) (Manifests, error) {
+	var manifests Manifests
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || (file != path && !isManifestFile(file)) {
+				return nil
+			}
+			return manifests.readFile(file)
+		})
+		if err != nil {
+			return Manifests{}, err
+		}
+	}
+	return manifests, nil
+}
+
+func isManifestFile(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func (m *Manifests) readFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.Decode(f, file)
+}
+
+// Decodes a stream of YAML documents separated by "---" or of JSON objects.
+// Errors name the source and the position of the document in it.
+func (m *Manifests) Decode(r io.Reader, source string) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for document := 1; ; document++ {
+		data, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s, document %d: %w", source, document, err)
+		}
+		// Documents that only contain comments are skipped
+		if converted, err := yaml.YAMLToJSON(data); err == nil && bytes.Equal(bytes.TrimSpace(converted), []byte("null")) {
+			continue
+		}
+		if err := m.decodeObject(data); err != nil {
+			return fmt.Errorf("%s, document %d: %w", source, document, err)
+		}
+	}
+}
+
+func (m *Manifests) decodeObject(data []byte) error {
+	object, kind, err := manifestDecoder.Decode(data, nil, nil)
+	if err != nil {
+		return err
+	}
+	switch typed := object.(type) {
+	case *v1.List:
+		// e.g. the output of kubectl get -o yaml
+		for i, item := range typed.Items {
+			if err := m.decodeObject(item.Raw); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+	case *v1.Namespace:
+		m.Namespaces = append(m.Namespaces, *typed)
+	case *scheduling.PriorityClass:
+		m.PriorityClasses = append(m.PriorityClasses, *typed)
+	case *v1.ConfigMap:
+		m.ConfigMaps = append(m.ConfigMaps, *typed)
+	case *cluster.MachineSet:
+		m.MachineSets = append(m.MachineSets, *typed)
+	case *cluster.Machine:
+		m.Machines = append(m.Machines, *typed)
+	case *v1.Node:
+		m.Nodes = append(m.Nodes, *typed)
+	case *apps.DaemonSet:
+		m.DaemonSets = append(m.DaemonSets, *typed)
+	default:
+		return fmt.Errorf("unsupported kind %s, supported kinds are %s", kind.GroupKind(), strings.Join(SupportedKinds, ", "))
+	}
+	return nil
+}
+
+// Returns the number of objects per kind
+func (m *Manifests) Counts() map[string]int {
+	return map[string]int{
+		"namespaces":      len(m.Namespaces),
+		"priorityClasses": len(m.PriorityClasses),
+		"configMaps":      len(m.ConfigMaps),
+		"machineSets":     len(m.MachineSets),
+		"machines":        len(m.Machines),
+		"nodes":           len(m.Nodes),
+		"daemonSets":      len(m.DaemonSets),
+	}
+}
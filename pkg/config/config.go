@@ -0,0 +1,205 @@
+package config
+
+import (
+	"fmt"
+	"go-kube/pkg/timeline"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// How the adapter serves an API group
+type APIGroupMode string
+
+const (
+	// The group is served, resources without storage return empty lists
+	Enabled APIGroupMode = "enabled"
+	// Discovery is served, every resource of the group returns an empty list and writes are rejected
+	Stubbed APIGroupMode = "stubbed"
+	// The group is neither listed in the discovery nor served
+	Disabled APIGroupMode = "disabled"
+)
+
+// API groups whose mode can be configured, the core group is always enabled
+var APIGroups = []string{"apps", "autoscaling", "batch", "cluster.x-k8s.io", "events.k8s.io", "policy", "scheduling.k8s.io", "storage.k8s.io"}
+
+// Settings of the adapter read from the YAML config file
+type Config struct {
+	// Address the adapter listens on, e.g. ":8000" or "127.0.0.1:8000"
+	ListenAddress string `json:"listenAddress"`
+	// Serves HTTPS instead of HTTP if set
+	TLS *TLSConfig `json:"tls,omitempty"`
+	// Mode per API group, groups that are not listed are enabled
+	APIGroups map[string]APIGroupMode `json:"apiGroups,omitempty"`
+	// Namespaces of a new or reset cluster, must contain "default"
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Files or directories of Kubernetes manifests loaded into the default cluster at startup
+	BootstrapManifests []string `json:"bootstrapManifests,omitempty"`
+	// Events of the scheduling timeline kept per cluster, older events are dropped
+	TimelineMaxEvents int `json:"timelineMaxEvents,omitempty"`
+	// Directory the results files of virtual clusters are created in, without it they cannot have one
+	ResultsDir string `json:"resultsDir,omitempty"`
+	// Addresses virtual clusters may serve their API on with an own listener
+	ClusterListenAddresses []string `json:"clusterListenAddresses,omitempty"`
+}
+
+type TLSConfig struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+}
+
+// Returns the configuration used without config file
+func Default() Config {
+	return Config{
+		ListenAddress:     ":8000",
+		Namespaces:        []string{"default"},
+		TimelineMaxEvents: timeline.DefaultMaxEvents,
+	}
+}
+
+// Reads the config file, settings that are not in the file keep their default
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	config := Default()
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return Config{}, fmt.Errorf("unable to parse config file %s: %w", path, err)
+	}
+	if errs := config.Validate(); len(errs) > 0 {
+		return Config{}, fmt.Errorf("invalid config file %s: %w", path, errs.ToAggregate())
+	}
+	return config, nil
+}
+
+func (c Config) Validate() field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateListenAddress(field.NewPath("listenAddress"), c.ListenAddress)...)
+
+	if c.TLS != nil {
+		path := field.NewPath("tls")
+		errs = append(errs, validateFile(path.Child("certFile"), c.TLS.CertFile)...)
+		errs = append(errs, validateFile(path.Child("keyFile"), c.TLS.KeyFile)...)
+	}
+
+	groups := make([]string, 0, len(c.APIGroups))
+	for group := range c.APIGroups {
+		groups = append(groups, group)
+	}
+	// Report the errors in a stable order
+	sort.Strings(groups)
+	for _, group := range groups {
+		path := field.NewPath("apiGroups").Key(group)
+		if !isConfigurableGroup(group) {
+			errs = append(errs, field.NotSupported(path, group, APIGroups))
+			continue
+		}
+		mode := c.APIGroups[group]
+		if mode != Enabled && mode != Stubbed && mode != Disabled {
+			errs = append(errs, field.NotSupported(path, mode, []string{string(Enabled), string(Stubbed), string(Disabled)}))
+		}
+	}
+
+	seen := make(map[string]bool, len(c.Namespaces))
+	for i, namespace := range c.Namespaces {
+		path := field.NewPath("namespaces").Index(i)
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			errs = append(errs, field.Invalid(path, namespace, msg))
+		}
+		if seen[namespace] {
+			errs = append(errs, field.Duplicate(path, namespace))
+		}
+		seen[namespace] = true
+	}
+	if !seen["default"] {
+		// The pod routes are bound to the default namespace
+		errs = append(errs, field.Required(field.NewPath("namespaces"), `must contain "default"`))
+	}
+
+	for i, address := range c.ClusterListenAddresses {
+		errs = append(errs, validateListenAddress(field.NewPath("clusterListenAddresses").Index(i), address)...)
+	}
+	if c.ResultsDir != "" {
+		path := field.NewPath("resultsDir")
+		if info, err := os.Stat(c.ResultsDir); err != nil {
+			errs = append(errs, field.Invalid(path, c.ResultsDir, err.Error()))
+		} else if !info.IsDir() {
+			errs = append(errs, field.Invalid(path, c.ResultsDir, "not a directory"))
+		}
+	}
+
+	if c.TimelineMaxEvents <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("timelineMaxEvents"), c.TimelineMaxEvents, "must be positive"))
+	}
+
+	for i, manifest := range c.BootstrapManifests {
+		path := field.NewPath("bootstrapManifests").Index(i)
+		if _, err := os.Stat(manifest); err != nil {
+			errs = append(errs, field.Invalid(path, manifest, err.Error()))
+		}
+	}
+	return errs
+}
+
+// Returns the mode of the API group
+func (c Config) APIGroupMode(group string) APIGroupMode {
+	if mode, found := c.APIGroups[group]; found {
+		return mode
+	}
+	return Enabled
+}
+
+func validateListenAddress(path *field.Path, address string) field.ErrorList {
+	if _, port, err := net.SplitHostPort(address); err != nil {
+		return field.ErrorList{field.Invalid(path, address, err.Error())}
+	} else if port == "" {
+		return field.ErrorList{field.Invalid(path, address, "the port is missing")}
+	}
+	return nil
+}
+
+// Returns whether virtual clusters may listen on the address
+func (c Config) IsClusterListenAddress(address string) bool {
+	for _, allowed := range c.ClusterListenAddresses {
+		if address == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the path of a results file of a virtual cluster, which has to be a relative path within the results directory
+func (c Config) ResultsPath(file string) (string, error) {
+	if c.ResultsDir == "" {
+		return "", fmt.Errorf("results files of virtual clusters require resultsDir in the config file")
+	}
+	if !filepath.IsLocal(file) {
+		return "", fmt.Errorf("results file %q has to be a relative path within the results directory", file)
+	}
+	return filepath.Join(c.ResultsDir, file), nil
+}
+
+func validateFile(path *field.Path, file string) field.ErrorList {
+	if file == "" {
+		return field.ErrorList{field.Required(path, "required for TLS")}
+	}
+	if _, err := os.Stat(file); err != nil {
+		return field.ErrorList{field.Invalid(path, file, err.Error())}
+	}
+	return nil
+}
+
+func isConfigurableGroup(group string) bool {
+	for _, configurable := range APIGroups {
+		if group == configurable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package control
+
+import (
+	"go-kube/pkg/admin"
+	"go-kube/pkg/storage"
+
+	"k8s.io/klog/v2"
+)
+
+type ApiUsageResource interface {
+	Get() admin.ApiUsageReport
+	// Clears the recorded requests and returns the report up to now
+	Delete() admin.ApiUsageReport
+}
+
+type ApiUsageResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl ApiUsageResourceImpl) Get() admin.ApiUsageReport {
+	return impl.storage.ApiUsage.GetApiUsage()
+}
+
+func (impl ApiUsageResourceImpl) Delete() admin.ApiUsageReport {
+	report := impl.storage.ApiUsage.GetApiUsage()
+	impl.storage.ApiUsage.Clear()
+	klog.V(1).Info("Cleared the API usage report")
+	return report
+}
+
+func NewApiUsageResource(storage *storage.StorageContainer) ApiUsageResourceImpl {
+	return ApiUsageResourceImpl{
+		storage: storage,
+	}
+}
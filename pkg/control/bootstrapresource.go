@@ -0,0 +1,191 @@
+package control
+
+import (
+	"fmt"
+	"go-kube/pkg/admin"
+	"go-kube/pkg/bootstrap"
+	"go-kube/pkg/storage"
+	"io"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+type BootstrapResource interface {
+	// Loads the manifests sent in the request body
+	Post(body io.Reader) (admin.BootstrapResponse, error)
+	// Loads the manifests into the storages
+	Load(manifests bootstrap.Manifests) (admin.BootstrapResponse, error)
+}
+
+type BootstrapResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl BootstrapResourceImpl) Post(body io.Reader) (admin.BootstrapResponse, error) {
+	var manifests bootstrap.Manifests
+	if err := manifests.Decode(body, "request body"); err != nil {
+		return admin.BootstrapResponse{}, apierrors.NewBadRequest(err.Error())
+	}
+	return impl.Load(manifests)
+}
+
+// Adds the objects to the storages, stored objects with the same name are replaced.
+// Watchers receive an ADDED or MODIFIED event for every object. Like the machine sets
+// sent by the simulator, loaded machine sets activate the cluster autoscaling.
+func (impl BootstrapResourceImpl) Load(manifests bootstrap.Manifests) (admin.BootstrapResponse, error) {
+	// Same lock order as the reset, pod and node updates must not interleave with the upserts
+	impl.storage.Pods.BeginTransaction()
+	defer impl.storage.Pods.EndTransaction()
+	impl.storage.Nodes.BeginTransaction()
+	defer impl.storage.Nodes.EndTransaction()
+
+	if err := impl.validate(&manifests); err != nil {
+		return admin.BootstrapResponse{}, err
+	}
+	objectIds := &IdGenerator{idStorage: impl.storage.ObjectIds}
+	machineIds := &IdGenerator{idStorage: impl.storage.MachineIds}
+	nodeIds := &IdGenerator{idStorage: impl.storage.NodeIds}
+
+	// Namespaces first, so that the objects in them are never seen without their namespace
+	for i := range manifests.Namespaces {
+		if manifests.Namespaces[i].Status.Phase == "" {
+			manifests.Namespaces[i].Status.Phase = v1.NamespaceActive
+		}
+	}
+	var events []metav1.WatchEvent
+	namespaces, _ := impl.storage.Namespaces.GetNamespaces()
+	namespaces.Items, events = upsertObjects(namespaces.Items, manifests.Namespaces, objectIds)
+	impl.storage.Namespaces.StoreNamespaces(namespaces, events)
+
+	priorityClasses, _ := impl.storage.PriorityClasses.GetPriorityClasses()
+	priorityClasses.Items, events = upsertObjects(priorityClasses.Items, manifests.PriorityClasses, objectIds)
+	impl.storage.PriorityClasses.StorePriorityClasses(priorityClasses, events)
+
+	configMaps, _ := impl.storage.ConfigMaps.GetConfigMaps()
+	configMaps.Items, events = upsertObjects(configMaps.Items, manifests.ConfigMaps, objectIds)
+	impl.storage.ConfigMaps.StoreConfigMaps(configMaps, events)
+
+	// Machine sets before their machines, and machines before their nodes, like InitMachinesNodes
+	if len(manifests.MachineSets) > 0 {
+		impl.storage.AdapterState.StoreClusterAutoscalerActive(true)
+	}
+	machineSets, _ := impl.storage.MachineSets.GetMachineSets()
+	machineSets.Items, events = upsertObjects(machineSets.Items, manifests.MachineSets, machineIds)
+	impl.storage.MachineSets.StoreMachineSets(machineSets, events)
+
+	machines, _ := impl.storage.Machines.GetMachines()
+	machines.Items, events = upsertObjects(machines.Items, manifests.Machines, machineIds)
+	// Machines created by scale-ups are numbered by the machine count. StoreMachines counts the
+	// ADDED machines, so machines replacing a stored one keep its number.
+	impl.storage.Machines.StoreMachines(machines, events)
+
+	nodes, _ := impl.storage.Nodes.GetNodes()
+	nodes.Items, events = upsertObjects(nodes.Items, manifests.Nodes, nodeIds)
+	impl.storage.Nodes.StoreNodes(nodes, events)
+
+	daemonSets, _ := impl.storage.DaemonSets.GetDaemonSets()
+	daemonSets.Items, events = upsertObjects(daemonSets.Items, manifests.DaemonSets, objectIds)
+	impl.storage.DaemonSets.StoreDaemonSets(daemonSets, events)
+
+	response := admin.BootstrapResponse{Loaded: manifests.Counts()}
+	klog.V(1).Infof("Loaded manifests: %v", response.Loaded)
+	return response, nil
+}
+
+func NewBootstrapResource(storage *storage.StorageContainer) BootstrapResourceImpl {
+	return BootstrapResourceImpl{
+		storage: storage,
+	}
+}
+
+// Checks names and namespaces of all objects before anything is stored. Namespaced
+// objects without namespace are put into the default namespace, like kubectl does.
+func (impl BootstrapResourceImpl) validate(manifests *bootstrap.Manifests) error {
+	namespaces := make(map[string]bool)
+	stored, _ := impl.storage.Namespaces.GetNamespaces()
+	for _, namespace := range stored.Items {
+		namespaces[namespace.Name] = true
+	}
+	for _, namespace := range manifests.Namespaces {
+		namespaces[namespace.Name] = true
+	}
+
+	var errs []error
+	errs = append(errs, validateObjects("Namespace", manifests.Namespaces, nil)...)
+	errs = append(errs, validateObjects("PriorityClass", manifests.PriorityClasses, nil)...)
+	errs = append(errs, validateObjects("ConfigMap", manifests.ConfigMaps, namespaces)...)
+	errs = append(errs, validateObjects("MachineSet", manifests.MachineSets, namespaces)...)
+	errs = append(errs, validateObjects("Machine", manifests.Machines, namespaces)...)
+	errs = append(errs, validateObjects("Node", manifests.Nodes, nil)...)
+	errs = append(errs, validateObjects("DaemonSet", manifests.DaemonSets, namespaces)...)
+	if len(errs) > 0 {
+		return apierrors.NewBadRequest(fmt.Sprintf("invalid manifests: %v", utilerrors.NewAggregate(errs)))
+	}
+	return nil
+}
+
+// Validates objects of one kind, namespaces is nil for cluster-scoped kinds
+func validateObjects[T any, PT objectPointer[T]](kind string, objects []T, namespaces map[string]bool) []error {
+	var errs []error
+	seen := make(map[string]bool, len(objects))
+	for i := range objects {
+		object := PT(&objects[i])
+		if object.GetName() == "" {
+			errs = append(errs, fmt.Errorf("%s %d has no name", kind, i+1))
+			continue
+		}
+		if namespaces == nil {
+			object.SetNamespace("")
+		} else if object.GetNamespace() == "" {
+			object.SetNamespace(metav1.NamespaceDefault)
+		}
+		key := objectKey(object)
+		if namespaces != nil && !namespaces[object.GetNamespace()] {
+			errs = append(errs, fmt.Errorf("%s %s: namespace %s does not exist", kind, key, object.GetNamespace()))
+		}
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%s %s is defined more than once", kind, key))
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+// Returns the stored objects with the loaded ones added or replacing the stored object
+// with the same namespace and name, and the events for the loaded objects.
+// Loaded objects get a new resourceVersion.
+func upsertObjects[T any, PT objectPointer[T]](stored []T, loaded []T, ids *IdGenerator) ([]T, []metav1.WatchEvent) {
+	result := make([]T, len(stored), len(stored)+len(loaded))
+	copy(result, stored)
+	index := make(map[string]int, len(result))
+	for i := range result {
+		index[objectKey(PT(&result[i]))] = i
+	}
+	events := make([]metav1.WatchEvent, 0, len(loaded))
+	for i := range loaded {
+		object := loaded[i]
+		PT(&object).SetResourceVersion(ids.GetNextResourceId())
+		if position, found := index[objectKey(PT(&object))]; found {
+			result[position] = object
+			events = append(events, metav1.WatchEvent{Type: "MODIFIED", Object: runtime.RawExtension{Object: PT(&object)}})
+		} else {
+			index[objectKey(PT(&object))] = len(result)
+			result = append(result, object)
+			events = append(events, metav1.WatchEvent{Type: "ADDED", Object: runtime.RawExtension{Object: PT(&object)}})
+		}
+	}
+	return result, events
+}
+
+// Returns namespace/name of namespaced objects and the name of cluster-scoped objects
+func objectKey(object metav1.Object) string {
+	if object.GetNamespace() == "" {
+		return object.GetName()
+	}
+	return object.GetNamespace() + "/" + object.GetName()
+}
@@ -0,0 +1,109 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"go-kube/pkg/misim"
+	"go-kube/pkg/storage"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+type ComponentsResource interface {
+	Get() []misim.Component
+	Ready(ctx context.Context, required []string, wait time.Duration) misim.ComponentsReadiness
+}
+
+type ComponentsResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl ComponentsResourceImpl) Get() []misim.Component {
+	components, _ := impl.storage.Components.GetComponents()
+	return components
+}
+
+// Checks whether the required components are connected and watch the resources they need,
+// waiting up to wait for them. Without required components the kube-scheduler is required,
+// and the cluster-autoscaler as well if the cluster autoscaling is active.
+func (impl ComponentsResourceImpl) Ready(ctx context.Context, required []string, wait time.Duration) misim.ComponentsReadiness {
+	if len(required) == 0 {
+		required = []string{"kube-scheduler"}
+		if impl.storage.AdapterState.IsClusterAutoscalerActive() {
+			required = append(required, "cluster-autoscaler")
+		}
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	components, broadcaster := impl.storage.Components.GetComponents()
+	for {
+		// Subscribing before checking makes sure that no change is missed
+		subscription := broadcaster.Subscribe()
+		components, _ = impl.storage.Components.GetComponents()
+		readiness := checkComponents(components, required)
+		if readiness.Ready || wait <= 0 {
+			broadcaster.CancelSubscription(subscription)
+			return readiness
+		}
+		klog.V(4).Infof("Waiting for components: %v", readiness.Missing)
+		for changed := false; !changed; {
+			select {
+			case <-ctx.Done():
+				broadcaster.CancelSubscription(subscription)
+				return readiness
+			case <-deadline.C:
+				broadcaster.CancelSubscription(subscription)
+				return readiness
+			case _, open := <-subscription:
+				if !open && broadcaster.Stopped() {
+					return readiness
+				}
+				// Check again
+				changed = true
+			}
+		}
+		broadcaster.CancelSubscription(subscription)
+	}
+}
+
+func checkComponents(components []misim.Component, required []string) misim.ComponentsReadiness {
+	readiness := misim.ComponentsReadiness{Required: required, Missing: []string{}, Components: components}
+	for _, name := range required {
+		// Several instances, e.g. of a restarted component, may share the name
+		watched := make(map[string]bool)
+		connected := false
+		for _, component := range components {
+			if component.Name != name {
+				continue
+			}
+			connected = true
+			for resource := range component.Watches {
+				watched[resource] = true
+			}
+		}
+		if !connected {
+			readiness.Missing = append(readiness.Missing, fmt.Sprintf("%s is not connected", name))
+			continue
+		}
+		var unwatched []string
+		for _, resource := range misim.ComponentWatches[name] {
+			if !watched[resource] {
+				unwatched = append(unwatched, resource)
+			}
+		}
+		sort.Strings(unwatched)
+		for _, resource := range unwatched {
+			readiness.Missing = append(readiness.Missing, fmt.Sprintf("%s does not watch %s", name, resource))
+		}
+	}
+	readiness.Ready = len(readiness.Missing) == 0
+	return readiness
+}
+
+func NewComponentsResource(storage *storage.StorageContainer) ComponentsResourceImpl {
+	return ComponentsResourceImpl{
+		storage: storage,
+	}
+}
@@ -0,0 +1,147 @@
+package control
+
+import (
+	"go-kube/pkg/dashboard"
+	"go-kube/pkg/storage"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Number of events shown by the dashboard
+const dashboardEventCount = 50
+
+type DashboardResource interface {
+	GetState() dashboard.State
+}
+
+type DashboardResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl DashboardResourceImpl) GetState() dashboard.State {
+	state := dashboard.State{
+		Round:       impl.storage.Timeline.GetRound(),
+		Nodes:       make([]dashboard.NodeState, 0),
+		PendingPods: make([]dashboard.PendingPod, 0),
+		MachineSets: make([]dashboard.MachineSetState, 0),
+	}
+
+	nodes, _ := impl.storage.Nodes.GetNodes()
+	for _, node := range nodes.Items {
+		nodeState := dashboard.NodeState{
+			Name:        node.Name,
+			Allocatable: make(map[string]resource.Quantity),
+			Requested:   make(map[string]resource.Quantity),
+			Pods:        make([]string, 0),
+		}
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods} {
+			if quantity, found := node.Status.Allocatable[name]; found {
+				nodeState.Allocatable[string(name)] = quantity
+			}
+		}
+		for _, pod := range impl.storage.Pods.GetPodsOnNode(node.Name) {
+			nodeState.Pods = append(nodeState.Pods, pod.Name)
+			addPodRequests(nodeState.Requested, pod)
+		}
+		state.Nodes = append(state.Nodes, nodeState)
+	}
+	sort.Slice(state.Nodes, func(i, j int) bool { return state.Nodes[i].Name < state.Nodes[j].Name })
+
+	pods, _ := impl.storage.Pods.GetPods()
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			continue
+		}
+		pendingPod := dashboard.PendingPod{Name: pod.Name}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse {
+				pendingPod.Message = condition.Message
+			}
+		}
+		state.PendingPods = append(state.PendingPods, pendingPod)
+	}
+
+	machineSets, _ := impl.storage.MachineSets.GetMachineSets()
+	for _, machineSet := range machineSets.Items {
+		machineSetState := dashboard.MachineSetState{
+			Name:        machineSet.Name,
+			MinReplicas: annotatedSize(machineSet.Annotations["cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"]),
+			MaxReplicas: annotatedSize(machineSet.Annotations["cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"]),
+			Machines:    len(impl.storage.Machines.GetMachinesOfMachineSet(machineSet.Name)),
+		}
+		if machineSet.Spec.Replicas != nil {
+			machineSetState.Replicas = *machineSet.Spec.Replicas
+		}
+		state.MachineSets = append(state.MachineSets, machineSetState)
+	}
+
+	state.Events = impl.recentEvents()
+	return state
+}
+
+// Returns the newest events of both event APIs
+func (impl DashboardResourceImpl) recentEvents() []dashboard.EventState {
+	events := make([]dashboard.EventState, 0)
+	for _, event := range impl.storage.Events.GetEventsApiEvents().Items {
+		eventTime := event.EventTime.Time
+		if eventTime.IsZero() {
+			eventTime = event.CreationTimestamp.Time
+		}
+		events = append(events, dashboard.EventState{
+			Time:    eventTime,
+			Type:    event.Type,
+			Reason:  event.Reason,
+			Object:  event.Regarding.Kind + "/" + event.Regarding.Name,
+			Message: event.Note,
+		})
+	}
+	for _, event := range impl.storage.Events.GetCoreApiEvents().Items {
+		eventTime := event.LastTimestamp.Time
+		if eventTime.IsZero() {
+			eventTime = event.EventTime.Time
+		}
+		if eventTime.IsZero() {
+			eventTime = event.CreationTimestamp.Time
+		}
+		events = append(events, dashboard.EventState{
+			Time:    eventTime,
+			Type:    event.Type,
+			Reason:  event.Reason,
+			Object:  event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			Message: event.Message,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.After(events[j].Time) })
+	if len(events) > dashboardEventCount {
+		events = events[:dashboardEventCount]
+	}
+	return events
+}
+
+// Adds the requests of the containers of the pod
+func addPodRequests(requested map[string]resource.Quantity, pod v1.Pod) {
+	for _, container := range pod.Spec.Containers {
+		for name, quantity := range container.Resources.Requests {
+			sum := requested[string(name)]
+			sum.Add(quantity)
+			requested[string(name)] = sum
+		}
+	}
+}
+
+func annotatedSize(annotation string) *int {
+	size, err := strconv.Atoi(annotation)
+	if err != nil {
+		return nil
+	}
+	return &size
+}
+
+func NewDashboardResource(storage *storage.StorageContainer) DashboardResourceImpl {
+	return DashboardResourceImpl{
+		storage: storage,
+	}
+}
@@ -0,0 +1,130 @@
+package control
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Pointer to a Kubernetes object, e.g. *v1.Pod
+type objectPointer[T any] interface {
+	*T
+	metav1.Object
+	runtime.Object
+}
+
+// Applies added, modified and deleted objects to the stored objects. Returns the
+// resulting objects and the watch events in the order they were applied.
+// Modified and deleted objects are rejected if they carry a resourceVersion
+// that does not match the stored one. The callers hold the transaction of the
+// storage from reading the stored objects until the result is stored.
+func applyDelta[T any, PT objectPointer[T]](resource schema.GroupResource, stored []T, added []T, modified []T, deleted []T, ids *IdGenerator) ([]T, []metav1.WatchEvent, error) {
+	result := make([]T, len(stored))
+	copy(result, stored)
+	index := make(map[string]int, len(result))
+	for i := range result {
+		index[PT(&result[i]).GetName()] = i
+	}
+
+	// Check everything before changing anything, so that a rejected delta leaves no traces
+	addedNames := make(map[string]bool, len(added))
+	for i := range added {
+		name := PT(&added[i]).GetName()
+		if _, found := index[name]; found {
+			return nil, nil, apierrors.NewAlreadyExists(resource, name)
+		}
+		if addedNames[name] {
+			return nil, nil, apierrors.NewBadRequest(fmt.Sprintf("%s %q is added more than once", resource.String(), name))
+		}
+		addedNames[name] = true
+	}
+	for _, objects := range [][]T{modified, deleted} {
+		for i := range objects {
+			if err := checkVersion[T, PT](resource, result, index, PT(&objects[i])); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	events := make([]metav1.WatchEvent, 0, len(added)+len(modified)+len(deleted))
+	for i := range added {
+		object := added[i]
+		PT(&object).SetResourceVersion(ids.GetNextResourceId())
+		result = append(result, object)
+		index[PT(&object).GetName()] = len(result) - 1
+		events = append(events, metav1.WatchEvent{Type: "ADDED", Object: runtime.RawExtension{Object: PT(&object)}})
+	}
+	for i := range modified {
+		object := modified[i]
+		PT(&object).SetResourceVersion(ids.GetNextResourceId())
+		result[index[PT(&object).GetName()]] = object
+		events = append(events, metav1.WatchEvent{Type: "MODIFIED", Object: runtime.RawExtension{Object: PT(&object)}})
+	}
+	if len(deleted) > 0 {
+		toDelete := make(map[string]bool, len(deleted))
+		for i := range deleted {
+			name := PT(&deleted[i]).GetName()
+			toDelete[name] = true
+			// Watchers receive the last stored state of the deleted object with a new resourceVersion
+			object := result[index[name]]
+			PT(&object).SetResourceVersion(ids.GetNextResourceId())
+			events = append(events, metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: PT(&object)}})
+		}
+		remaining := result[:0]
+		for _, object := range result {
+			if !toDelete[PT(&object).GetName()] {
+				remaining = append(remaining, object)
+			}
+		}
+		result = remaining
+	}
+	return result, events, nil
+}
+
+func checkVersion[T any, PT objectPointer[T]](resource schema.GroupResource, stored []T, index map[string]int, object PT) error {
+	name := object.GetName()
+	i, found := index[name]
+	if !found {
+		return apierrors.NewNotFound(resource, name)
+	}
+	storedVersion := PT(&stored[i]).GetResourceVersion()
+	if object.GetResourceVersion() != "" && object.GetResourceVersion() != storedVersion {
+		return apierrors.NewConflict(resource, name, fmt.Errorf("the object has been modified, stored resourceVersion is %s but %s was sent", storedVersion, object.GetResourceVersion()))
+	}
+	return nil
+}
+
+// Compares the stored objects with the desired ones. Objects are matched by name,
+// resourceVersions are ignored when comparing and not carried over to modified objects.
+func diffObjects[T any, PT objectPointer[T]](stored []T, desired []T) (added []T, modified []T, deleted []T) {
+	storedByName := make(map[string]PT, len(stored))
+	for i := range stored {
+		storedByName[PT(&stored[i]).GetName()] = PT(&stored[i])
+	}
+	desiredNames := make(map[string]bool, len(desired))
+	for i := range desired {
+		object := desired[i]
+		name := PT(&object).GetName()
+		desiredNames[name] = true
+		storedObject, found := storedByName[name]
+		if !found {
+			added = append(added, object)
+			continue
+		}
+		PT(&object).SetResourceVersion(storedObject.GetResourceVersion())
+		if !equality.Semantic.DeepEqual(PT(&object), storedObject) {
+			PT(&object).SetResourceVersion("")
+			modified = append(modified, object)
+		}
+	}
+	for i := range stored {
+		if !desiredNames[PT(&stored[i]).GetName()] {
+			deleted = append(deleted, stored[i])
+		}
+	}
+	return added, modified, deleted
+}
@@ -0,0 +1,216 @@
+package control
+
+import (
+	"fmt"
+	"go-kube/pkg/storage/inmemorystorage"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPod(name string, resourceVersion string) v1.Pod {
+	return v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", ResourceVersion: resourceVersion}}
+}
+
+// Returns "TYPE name resourceVersion" for every event
+func describeEvents(events []metav1.WatchEvent) []string {
+	described := make([]string, 0, len(events))
+	for _, event := range events {
+		object := event.Object.Object.(metav1.Object)
+		described = append(described, fmt.Sprintf("%s %s %s", event.Type, object.GetName(), object.GetResourceVersion()))
+	}
+	return described
+}
+
+// Returns nil for no pods
+func podNames(pods []v1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestApplyDelta(t *testing.T) {
+	tests := []struct {
+		name     string
+		added    []v1.Pod
+		modified []v1.Pod
+		deleted  []v1.Pod
+		// Checks the returned error, nil if the delta has to be applied
+		wantErr    func(error) bool
+		wantNames  []string
+		wantEvents []string
+	}{
+		{
+			name:       "added",
+			added:      []v1.Pod{testPod("c", "")},
+			wantNames:  []string{"a", "b", "c"},
+			wantEvents: []string{"ADDED c 10"},
+		},
+		{
+			name:    "already exists",
+			added:   []v1.Pod{testPod("a", "")},
+			wantErr: apierrors.IsAlreadyExists,
+		},
+		{
+			name:    "duplicate added",
+			added:   []v1.Pod{testPod("c", ""), testPod("c", "")},
+			wantErr: apierrors.IsBadRequest,
+		},
+		{
+			name:       "modified with the stored resourceVersion",
+			modified:   []v1.Pod{testPod("a", "1")},
+			wantNames:  []string{"a", "b"},
+			wantEvents: []string{"MODIFIED a 10"},
+		},
+		{
+			name:       "modified without resourceVersion",
+			modified:   []v1.Pod{testPod("a", "")},
+			wantNames:  []string{"a", "b"},
+			wantEvents: []string{"MODIFIED a 10"},
+		},
+		{
+			name:     "resourceVersion conflict",
+			modified: []v1.Pod{testPod("a", "2")},
+			wantErr:  apierrors.IsConflict,
+		},
+		{
+			name:     "modified unknown",
+			modified: []v1.Pod{testPod("x", "")},
+			wantErr:  apierrors.IsNotFound,
+		},
+		{
+			name:       "deleted carries a new resourceVersion",
+			deleted:    []v1.Pod{testPod("b", "2")},
+			wantNames:  []string{"a"},
+			wantEvents: []string{"DELETED b 10"},
+		},
+		{
+			name:    "deleted conflict",
+			deleted: []v1.Pod{testPod("b", "1")},
+			wantErr: apierrors.IsConflict,
+		},
+		{
+			name:       "applied in order",
+			added:      []v1.Pod{testPod("c", "")},
+			modified:   []v1.Pod{testPod("b", "")},
+			deleted:    []v1.Pod{testPod("a", "")},
+			wantNames:  []string{"b", "c"},
+			wantEvents: []string{"ADDED c 10", "MODIFIED b 11", "DELETED a 12"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stored := []v1.Pod{testPod("a", "1"), testPod("b", "2")}
+			idStorage := inmemorystorage.NewIdInMemoryStorage()
+			idStorage.StoreNextId(10)
+			ids := &IdGenerator{idStorage: &idStorage}
+
+			result, events, err := applyDelta(v1.Resource("pods"), stored, test.added, test.modified, test.deleted, ids)
+			if test.wantErr != nil {
+				if err == nil || !test.wantErr(err) {
+					t.Fatalf("applyDelta returned error %v", err)
+				}
+				if idStorage.GetNextId() != 10 {
+					t.Fatal("rejected delta used resourceVersions")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyDelta returned error %v", err)
+			}
+			if names := podNames(result); !reflect.DeepEqual(names, test.wantNames) {
+				t.Fatalf("applyDelta returned pods %v, want %v", names, test.wantNames)
+			}
+			if described := describeEvents(events); !reflect.DeepEqual(described, test.wantEvents) {
+				t.Fatalf("applyDelta returned events %v, want %v", described, test.wantEvents)
+			}
+			if stored[0].ResourceVersion != "1" || stored[1].ResourceVersion != "2" {
+				t.Fatal("applyDelta changed the stored pods")
+			}
+		})
+	}
+}
+
+func TestDiffObjects(t *testing.T) {
+	labeled := testPod("b", "")
+	labeled.Labels = map[string]string{"changed": "true"}
+	tests := []struct {
+		name         string
+		desired      []v1.Pod
+		wantAdded    []string
+		wantModified []string
+		wantDeleted  []string
+	}{
+		{
+			name:    "unchanged",
+			desired: []v1.Pod{testPod("a", ""), testPod("b", "")},
+		},
+		{
+			// The resourceVersions of the simulation are not compared
+			name:    "unchanged with other resourceVersions",
+			desired: []v1.Pod{testPod("a", "7"), testPod("b", "8")},
+		},
+		{
+			name:         "added, modified and deleted",
+			desired:      []v1.Pod{labeled, testPod("c", "")},
+			wantAdded:    []string{"c"},
+			wantModified: []string{"b"},
+			wantDeleted:  []string{"a"},
+		},
+		{
+			name:        "all deleted",
+			desired:     nil,
+			wantDeleted: []string{"a", "b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stored := []v1.Pod{testPod("a", "1"), testPod("b", "2")}
+			added, modified, deleted := diffObjects(stored, test.desired)
+			for _, diff := range []struct {
+				kind string
+				got  []v1.Pod
+				want []string
+			}{{"added", added, test.wantAdded}, {"modified", modified, test.wantModified}, {"deleted", deleted, test.wantDeleted}} {
+				if names := podNames(diff.got); !reflect.DeepEqual(names, diff.want) {
+					t.Fatalf("diffObjects returned %s pods %v, want %v", diff.kind, names, diff.want)
+				}
+			}
+			// Modified pods are applied without version check
+			for _, pod := range modified {
+				if pod.ResourceVersion != "" {
+					t.Fatalf("modified pod %s carries resourceVersion %s", pod.Name, pod.ResourceVersion)
+				}
+			}
+
+			// Applying the diff yields the desired pods with their watch events
+			idStorage := inmemorystorage.NewIdInMemoryStorage()
+			idStorage.StoreNextId(10)
+			synced, events, err := applyDelta(v1.Resource("pods"), stored, added, modified, deleted, &IdGenerator{idStorage: &idStorage})
+			if err != nil {
+				t.Fatalf("applying the diff returned error %v", err)
+			}
+			if len(synced) != len(test.desired) {
+				t.Fatalf("applying the diff returned %v, want %d pods", podNames(synced), len(test.desired))
+			}
+			if len(events) != len(added)+len(modified)+len(deleted) {
+				t.Fatalf("applying the diff returned events %v", describeEvents(events))
+			}
+		})
+	}
+}
+
+// Two desired pods with the same name are both added, applying the diff rejects them
+func TestDiffObjectsDuplicateDesired(t *testing.T) {
+	stored := []v1.Pod{testPod("a", "1")}
+	added, modified, deleted := diffObjects(stored, []v1.Pod{testPod("a", ""), testPod("c", ""), testPod("c", "")})
+	idStorage := inmemorystorage.NewIdInMemoryStorage()
+	if _, _, err := applyDelta(v1.Resource("pods"), stored, added, modified, deleted, &IdGenerator{idStorage: &idStorage}); !apierrors.IsBadRequest(err) {
+		t.Fatalf("applying the diff returned error %v, want BadRequest", err)
+	}
+}
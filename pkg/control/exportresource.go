@@ -0,0 +1,61 @@
+package control
+
+import (
+	"go-kube/pkg/bootstrap"
+	"go-kube/pkg/storage"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+type ExportResource interface {
+	Get() []runtime.Object
+}
+
+type ExportResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+// Returns the current cluster as objects that can be applied to a real cluster: namespaces,
+// machine sets, machines, nodes and the bound pods pinned to their node. Status and runtime
+// fields are stripped, see the Export functions of the bootstrap package.
+func (impl ExportResourceImpl) Get() []runtime.Object {
+	var objects []runtime.Object
+	namespaces, _ := impl.storage.Namespaces.GetNamespaces()
+	for _, namespace := range namespaces.Items {
+		if exported, ok := bootstrap.ExportNamespace(namespace); ok {
+			objects = append(objects, &exported)
+		}
+	}
+	machineSets, _ := impl.storage.MachineSets.GetMachineSets()
+	for _, machineSet := range machineSets.Items {
+		exported := bootstrap.ExportMachineSet(machineSet)
+		objects = append(objects, &exported)
+	}
+	machines, _ := impl.storage.Machines.GetMachines()
+	for _, machine := range machines.Items {
+		exported := bootstrap.ExportMachine(machine)
+		objects = append(objects, &exported)
+	}
+	nodes, _ := impl.storage.Nodes.GetNodes()
+	for _, node := range nodes.Items {
+		exported := bootstrap.ExportNode(node)
+		objects = append(objects, &exported)
+	}
+	pods, _ := impl.storage.Pods.GetPods()
+	exportedPods := 0
+	for _, pod := range pods.Items {
+		if exported, ok := bootstrap.ExportPod(pod); ok {
+			objects = append(objects, &exported)
+			exportedPods++
+		}
+	}
+	klog.V(3).Infof("Exporting %d objects, %d of %d pods are bound", len(objects), exportedPods, len(pods.Items))
+	return objects
+}
+
+func NewExportResource(storage *storage.StorageContainer) ExportResourceImpl {
+	return ExportResourceImpl{
+		storage: storage,
+	}
+}
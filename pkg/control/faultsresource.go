@@ -0,0 +1,52 @@
+package control
+
+import (
+	"fmt"
+	"go-kube/pkg/admin"
+	"go-kube/pkg/faults"
+	"go-kube/pkg/storage"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+)
+
+type FaultsResource interface {
+	Get() admin.FaultConfig
+	// Replaces the injected faults, the random source starts again from the seed
+	Put(config admin.FaultConfig) (admin.FaultConfig, error)
+	// Stops injecting faults
+	Delete() admin.FaultConfig
+}
+
+type FaultsResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl FaultsResourceImpl) Get() admin.FaultConfig {
+	return impl.storage.Faults.GetFaults().Config()
+}
+
+func (impl FaultsResourceImpl) Put(config admin.FaultConfig) (admin.FaultConfig, error) {
+	if errs := faults.Validate(config); len(errs) > 0 {
+		return admin.FaultConfig{}, apierrors.NewBadRequest(fmt.Sprintf("invalid fault config: %v", errs.ToAggregate()))
+	}
+	if config.Rules == nil {
+		config.Rules = []admin.FaultRule{}
+	}
+	impl.storage.Faults.StoreFaults(faults.NewInjector(config))
+	klog.V(1).Infof("Injecting faults with %d rules and seed %d", len(config.Rules), config.Seed)
+	return config, nil
+}
+
+func (impl FaultsResourceImpl) Delete() admin.FaultConfig {
+	config := admin.FaultConfig{Rules: []admin.FaultRule{}}
+	impl.storage.Faults.StoreFaults(faults.NewInjector(config))
+	klog.V(1).Info("Stopped injecting faults")
+	return config
+}
+
+func NewFaultsResource(storage *storage.StorageContainer) FaultsResourceImpl {
+	return FaultsResourceImpl{
+		storage: storage,
+	}
+}
@@ -11,11 +11,19 @@ import (
 )
 
 type NodeController struct {
-	storage *storage.StorageContainer
+	storage     *storage.StorageContainer
+	idGenerator IdGenerator
 }
 
 func (c NodeController) UpdateNodes(nodes v1.NodeList, events []metav1.WatchEvent) misim.NodeUpdateResponse {
 	klog.V(3).Info("Node-Update: ", len(nodes.Items), " nodes")
+	c.storage.Nodes.BeginTransaction()
+	defer c.storage.Nodes.EndTransaction()
+	return c.storeNodes(nodes, events)
+}
+
+// Stores the nodes, the caller holds the node transaction
+func (c NodeController) storeNodes(nodes v1.NodeList, events []metav1.WatchEvent) misim.NodeUpdateResponse {
 	c.storage.Nodes.StoreNodes(nodes, events)
 	return misim.NodeUpdateResponse{
 		Data: nodes,
@@ -23,6 +31,13 @@ func (c NodeController) UpdateNodes(nodes v1.NodeList, events []metav1.WatchEven
 }
 
 func (c NodeController) InitMachinesNodes(nodes v1.NodeList, events []metav1.WatchEvent, machineSets []cluster.MachineSet, machines []cluster.Machine) misim.NodeUpdateResponse {
+	c.storage.Nodes.BeginTransaction()
+	defer c.storage.Nodes.EndTransaction()
+	return c.initMachinesNodes(nodes, events, machineSets, machines)
+}
+
+// Stores the machine sets, machines and nodes, the caller holds the node transaction
+func (c NodeController) initMachinesNodes(nodes v1.NodeList, events []metav1.WatchEvent, machineSets []cluster.MachineSet, machines []cluster.Machine) misim.NodeUpdateResponse {
 	klog.V(3).Infof("Machine-Node-Init: %d nodes, %d machine sets, %d machines", len(nodes.Items), len(machineSets), len(machines))
 
 	// Activate the cluster autoscaling!
@@ -61,8 +76,48 @@ func (c NodeController) InitMachinesNodes(nodes v1.NodeList, events []metav1.Wat
 	}
 }
 
+func (c *NodeController) UpdateNodesDelta(delta misim.NodeDeltaRequest) (misim.NodeUpdateResponse, error) {
+	klog.V(3).Infof("Node-Delta: %d added, %d modified, %d deleted", len(delta.Added), len(delta.Modified), len(delta.Deleted))
+	// Other updates must not change the nodes between the version check and storing the result
+	c.storage.Nodes.BeginTransaction()
+	defer c.storage.Nodes.EndTransaction()
+	storedNodes, _ := c.storage.Nodes.GetNodes()
+	nodes, events, err := applyDelta(v1.Resource("nodes"), storedNodes.Items, delta.Added, delta.Modified, delta.Deleted, &c.idGenerator)
+	if err != nil {
+		return misim.NodeUpdateResponse{}, err
+	}
+	nodeList := v1.NodeList{TypeMeta: metav1.TypeMeta{Kind: "NodeList", APIVersion: "v1"}, Items: nodes}
+	c.storage.Nodes.StoreNodes(nodeList, events)
+	return misim.NodeUpdateResponse{
+		Data: nodeList,
+	}, nil
+}
+
+// Like UpdateNodes and InitMachinesNodes, but turns the stored nodes into the passed ones and derives the
+// required watch events. The diff is computed and stored within the node transaction, so concurrent syncs
+// are applied one after another. If the diff cannot be applied, nothing is changed.
+func (c *NodeController) SyncNodes(nodes v1.NodeList, machineSets []cluster.MachineSet, machines []cluster.Machine) (misim.NodeUpdateResponse, error) {
+	c.storage.Nodes.BeginTransaction()
+	defer c.storage.Nodes.EndTransaction()
+	storedNodes, _ := c.storage.Nodes.GetNodes()
+	added, modified, deleted := diffObjects(storedNodes.Items, nodes.Items)
+	klog.V(3).Infof("Node-Sync: %d added, %d modified, %d deleted", len(added), len(modified), len(deleted))
+	synced, events, err := applyDelta(v1.Resource("nodes"), storedNodes.Items, added, modified, deleted, &c.idGenerator)
+	if err != nil {
+		return misim.NodeUpdateResponse{}, err
+	}
+	syncedList := v1.NodeList{TypeMeta: metav1.TypeMeta{Kind: "NodeList", APIVersion: "v1"}, Items: synced}
+	if len(machineSets) == 0 {
+		return c.storeNodes(syncedList, events), nil
+	}
+	return c.initMachinesNodes(syncedList, events, machineSets, machines), nil
+}
+
 func NewNodeController(storage *storage.StorageContainer) NodeController {
 	return NodeController{
 		storage: storage,
+		idGenerator: IdGenerator{
+			idStorage: storage.NodeIds,
+		},
 	}
 }
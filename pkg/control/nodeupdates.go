@@ -3,30 +3,59 @@ package control
 import (
 	"go-kube/pkg/misim"
 	"go-kube/pkg/storage"
+
+	"k8s.io/klog/v2"
 )
 
 type NodeUpdatesResource interface {
-	Post(misim.NodeUpdateRequest) misim.NodeUpdateResponse
+	Post(misim.NodeUpdateRequest) (misim.NodeUpdateResponse, error)
+	PostDelta(misim.NodeDeltaRequest) (misim.NodeUpdateResponse, error)
+	PostSync(misim.NodeUpdateRequest) (misim.NodeUpdateResponse, error)
 }
 
 type NodeUpdatesResourceImpl struct {
-	storage *storage.StorageContainer
+	storage          *storage.StorageContainer
+	strictValidation bool
 }
 
-func (impl NodeUpdatesResourceImpl) Post(u misim.NodeUpdateRequest) misim.NodeUpdateResponse {
+func (impl NodeUpdatesResourceImpl) Post(u misim.NodeUpdateRequest) (misim.NodeUpdateResponse, error) {
+	if err := checkValidation("NodeUpdateRequest", misim.ValidateNodeUpdateRequest(u), impl.strictValidation); err != nil {
+		return misim.NodeUpdateResponse{}, err
+	}
 	controller := NewNodeController(impl.storage)
 
 	if u.MachineSets == nil || len(u.MachineSets) == 0 {
 		// No cluster scaling
-		return controller.UpdateNodes(u.AllNodes, u.Events)
+		return controller.UpdateNodes(u.AllNodes, u.Events), nil
 	} else {
 		// If the request contains machines set, we use only the machines
-		return controller.InitMachinesNodes(u.AllNodes, u.Events, u.MachineSets, u.Machines)
+		return controller.InitMachinesNodes(u.AllNodes, u.Events, u.MachineSets, u.Machines), nil
+	}
+}
+
+func (impl NodeUpdatesResourceImpl) PostDelta(u misim.NodeDeltaRequest) (misim.NodeUpdateResponse, error) {
+	if err := checkValidation("NodeDeltaRequest", misim.ValidateNodeDeltaRequest(u), impl.strictValidation); err != nil {
+		return misim.NodeUpdateResponse{}, err
+	}
+	controller := NewNodeController(impl.storage)
+	return controller.UpdateNodesDelta(u)
+}
+
+// Like Post, but the watch events are derived from the difference between the stored and the passed nodes
+func (impl NodeUpdatesResourceImpl) PostSync(u misim.NodeUpdateRequest) (misim.NodeUpdateResponse, error) {
+	if err := checkValidation("NodeUpdateRequest", misim.ValidateNodeUpdateRequest(misim.NodeUpdateRequest{AllNodes: u.AllNodes, MachineSets: u.MachineSets, Machines: u.Machines}), impl.strictValidation); err != nil {
+		return misim.NodeUpdateResponse{}, err
+	}
+	controller := NewNodeController(impl.storage)
+	if len(u.Events) > 0 {
+		klog.V(2).Infof("Node-Sync: ignoring %d events sent by the simulation", len(u.Events))
 	}
+	return controller.SyncNodes(u.AllNodes, u.MachineSets, u.Machines)
 }
 
-func NewNodeUpdateResource(storage *storage.StorageContainer) NodeUpdatesResourceImpl {
+func NewNodeUpdateResource(storage *storage.StorageContainer, strictValidation bool) NodeUpdatesResourceImpl {
 	return NodeUpdatesResourceImpl{
-		storage: storage,
+		storage:          storage,
+		strictValidation: strictValidation,
 	}
 }
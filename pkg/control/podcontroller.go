@@ -1,14 +1,19 @@
 package control
 
 import (
+	"go-kube/internal/metrics"
 	"go-kube/pkg/misim"
 	"go-kube/pkg/storage"
+	"go-kube/pkg/timeline"
 	core "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 	"strconv"
 	"sync"
+	"time"
 )
 
 type PodController struct {
@@ -20,40 +25,116 @@ type PodController struct {
 func (c *PodController) UpdatePods(ur v1.PodList, events []metav1.WatchEvent, podsToBePlaced v1.PodList, deleteEvents bool) misim.PodsUpdateResponse {
 	if !deleteEvents {
 		klog.V(3).Info("Pod-Update: ", len(ur.Items), " pods, ", len(podsToBePlaced.Items), " to be placed")
-		// Buffers have to be cleared before storing new pods
-		c.storage.Pods.PodsToBePlaced().Clear()
-		c.storage.Pods.PodsToBePlaced().PutAll(podsToBePlaced.Items)
-		c.storage.Pods.FailedPodBuffer().Clear()
-		c.storage.Pods.BindedPodBuffer().Clear()
-		c.storage.AdapterState.StoreClusterAutoscalingDone(false)
-		c.storage.Nodes.NewNodes().Clear()
-		c.storage.Nodes.DeletedNodes().Clear()
-
-		// Store pods
-		c.storage.Pods.StorePods(ur, events)
-
-		// If there were pods to be placed, wait for the response
-		if !c.storage.Pods.PodsToBePlaced().Empty() {
-			podUpdateChannel := c.storage.Pods.PodsUpdateChannel().InitChannel()
-			//c.storage.Pods.PodsUpdateChannel().InitChannel()
-			klog.V(3).Infof("Wait for pods to be placed...")
-			// wait for it
-			select {
-			case response := <-podUpdateChannel:
-				return response
-			}
-		} else {
-			return c.createDefaultResponse()
-		}
+		response, _ := c.updatePods(func() (v1.PodList, []metav1.WatchEvent, error) {
+			return ur, events, nil
+		}, podsToBePlaced)
+		return response
 	} else {
 		klog.V(3).Info("Pod-Update: Deleted pods")
+		c.storage.Pods.BeginTransaction()
 		c.storage.Pods.DeletePods(events)
+		c.storage.Pods.EndTransaction()
 	}
 	return c.createDefaultResponse()
 }
 
+// Stores the pods returned by update and waits until the pods to be placed are bound or failed.
+// update runs within the pod transaction, so no other update or binding can change the pods
+// between reading and storing them. If it fails, nothing is changed.
+func (c *PodController) updatePods(update func() (v1.PodList, []metav1.WatchEvent, error), podsToBePlaced v1.PodList) (misim.PodsUpdateResponse, error) {
+	c.storage.Pods.BeginTransaction()
+	pods, events, err := update()
+	if err != nil {
+		c.storage.Pods.EndTransaction()
+		return misim.PodsUpdateResponse{}, err
+	}
+	// Buffers have to be cleared before storing new pods
+	c.storage.Pods.PodsToBePlaced().Clear()
+	c.storage.Pods.PodsToBePlaced().PutAll(podsToBePlaced.Items)
+	if len(podsToBePlaced.Items) > 0 {
+		c.storage.Timeline.StartRound()
+		for _, pod := range podsToBePlaced.Items {
+			c.storage.Timeline.Record(pod.Name, timeline.Received, nil)
+		}
+	}
+	c.storage.Pods.FailedPodBuffer().Clear()
+	c.storage.Pods.BindedPodBuffer().Clear()
+	c.storage.AdapterState.StoreClusterAutoscalingDone(false)
+	c.storage.Nodes.NewNodes().Clear()
+	c.storage.Nodes.DeletedNodes().Clear()
+
+	// Store pods
+	c.storage.Pods.StorePods(pods, events)
+
+	// If there were pods to be placed, wait for the response
+	if c.storage.Pods.PodsToBePlaced().Empty() {
+		c.storage.Pods.EndTransaction()
+		return c.createDefaultResponse(), nil
+	}
+	podUpdateChannel := c.storage.Pods.PodsUpdateChannel().InitChannel()
+	// Bindings wait for the transaction, so they are only reported once the channel is set up
+	c.storage.Pods.EndTransaction()
+	klog.V(3).Infof("Wait for pods to be placed...")
+	roundStart := time.Now()
+	// wait for it
+	response := <-podUpdateChannel
+	metrics.SchedulingRoundDuration.WithLabelValues(c.storage.Cluster).Observe(time.Since(roundStart).Seconds())
+	metrics.SchedulingRoundPods.WithLabelValues(c.storage.Cluster, "bound").Observe(float64(len(response.Binded)))
+	metrics.SchedulingRoundPods.WithLabelValues(c.storage.Cluster, "failed").Observe(float64(len(response.Failed)))
+	return response, nil
+}
+
+func (c *PodController) UpdatePodsDelta(delta misim.PodsDeltaRequest) (misim.PodsUpdateResponse, error) {
+	klog.V(3).Infof("Pod-Delta: %d added, %d modified, %d deleted", len(delta.Added), len(delta.Modified), len(delta.Deleted))
+	return c.updatePods(func() (v1.PodList, []metav1.WatchEvent, error) {
+		storedPods, _ := c.storage.Pods.GetPods()
+		pods, events, err := applyDelta(v1.Resource("pods"), storedPods.Items, delta.Added, delta.Modified, delta.Deleted, &c.idGenerator)
+		if err != nil {
+			return v1.PodList{}, nil, err
+		}
+		// Checked against the pods read within the transaction, so no other update can remove them before the round
+		if errs := misim.ValidatePodsToBePlacedExist(delta.PodsToBePlaced, pods); len(errs) > 0 {
+			return v1.PodList{}, nil, apierrors.NewInvalid(schema.GroupKind{Group: "misim", Kind: "PodsDeltaRequest"}, "", errs)
+		}
+		return v1.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}, Items: pods}, events, nil
+	}, delta.PodsToBePlaced)
+}
+
+// Like UpdatePods, but turns the stored pods into the passed ones and derives the required watch events.
+// The diff is computed and stored within the pod transaction, so concurrent syncs are applied one after another.
+// If the diff cannot be applied, e.g. because pods has two pods of the same name, nothing is changed.
+func (c *PodController) SyncPods(pods v1.PodList, podsToBePlaced v1.PodList) (misim.PodsUpdateResponse, error) {
+	return c.updatePods(func() (v1.PodList, []metav1.WatchEvent, error) {
+		storedPods, _ := c.storage.Pods.GetPods()
+		added, modified, deleted := diffObjects(storedPods.Items, pods.Items)
+		klog.V(3).Infof("Pod-Sync: %d added, %d modified, %d deleted, %d to be placed", len(added), len(modified), len(deleted), len(podsToBePlaced.Items))
+		synced, events, err := applyDelta(v1.Resource("pods"), storedPods.Items, added, modified, deleted, &c.idGenerator)
+		if err != nil {
+			return v1.PodList{}, nil, err
+		}
+		return v1.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}, Items: synced}, events, nil
+	}, podsToBePlaced)
+}
+
 // Generates an update about all the pods that should be placed
 func (c *PodController) createDefaultResponse() misim.PodsUpdateResponse {
+	return c.createResponse("No new situation for the scheduler")
+}
+
+// Answers a pods update that is waiting for the scheduler, pods without outcome are reported
+// as failed with the passed reason. Returns false if no pods update is waiting. The caller
+// holds the pod transaction, so that no binding changes the buffers meanwhile.
+func (c *PodController) AbortRound(reason string) bool {
+	if !c.storage.Pods.PodsUpdateChannel().Send(c.createResponse(reason)) {
+		return false
+	}
+	klog.V(1).Info("Answered pending pods update: ", reason)
+	return true
+}
+
+// Generates an update about all the pods that should be placed, pods the
+// scheduler has not reported yet fail with the passed message
+func (c *PodController) createResponse(unreportedMessage string) misim.PodsUpdateResponse {
 	failedList := make([]misim.BindingFailureInformation, 0)
 	bindedList := make([]misim.BindingInformation, 0)
 	for _, pod := range c.storage.Pods.PodsToBePlaced().Items() {
@@ -79,7 +160,7 @@ func (c *PodController) createDefaultResponse() misim.PodsUpdateResponse {
 		if podReported == true {
 			continue
 		}
-		failedList = append(failedList, misim.BindingFailureInformation{Pod: pod.Name, Message: "No new situation for the scheduler"})
+		failedList = append(failedList, misim.BindingFailureInformation{Pod: pod.Name, Message: unreportedMessage})
 	}
 	if c.storage.Pods.FailedPodBuffer().Empty() && c.storage.Pods.BindedPodBuffer().Empty() && c.storage.AdapterState.IsClusterAutoscalerActive() && !c.storage.AdapterState.IsClusterAutoscalingDone() && c.storage.MachineSets.IsDownscalingPossible() {
 		// TODO [Cluster Downscaling]: Integrate downscaling
@@ -115,6 +196,9 @@ func (c *PodController) BindPod(podName string, nodeName string) {
 	})
 
 	c.storage.Pods.UpdatePod(podName, pod)
+	c.storage.Timeline.RecordOnce(podName, timeline.FirstSchedulingAttempt, map[string]string{"result": "bound"})
+	c.storage.Timeline.Record(podName, timeline.Bound, map[string]string{"node": nodeName})
+	c.storage.Progress.Publish(misim.StreamMessage{Type: misim.StreamBinding, Binding: &bindingInformation})
 	c.updatePodChannel()
 
 	c.storage.Pods.EndTransaction()
@@ -141,11 +225,30 @@ func (c *PodController) FailedPod(podName string, status core.PodStatus) {
 	pod.ObjectMeta.ResourceVersion = c.idGenerator.GetNextResourceId()
 
 	c.storage.Pods.UpdatePod(podName, pod)
+	c.storage.Timeline.RecordOnce(podName, timeline.FirstSchedulingAttempt, map[string]string{"result": "failed"})
+	c.storage.Timeline.Record(podName, timeline.SchedulingFailed, map[string]string{"reason": status.Conditions[0].Reason, "message": status.Conditions[0].Message})
+	c.storage.Progress.Publish(misim.StreamMessage{Type: misim.StreamFailure, Failure: &failureInformation})
 	c.updatePodChannel()
 
 	c.storage.Pods.EndTransaction()
 }
 
+// Removes the pod and announces the eviction, returns a NotFound error if there is no such pod
+func (c *PodController) EvictPod(podName string) error {
+	c.storage.Pods.BeginTransaction()
+	defer c.storage.Pods.EndTransaction()
+
+	// Remove the pod, the simulation decides whether it is recreated
+	pod := c.storage.Pods.DeletePod(podName)
+	if pod.Name == "" {
+		return apierrors.NewNotFound(v1.Resource("pods"), podName)
+	}
+	klog.V(3).Info("Evicted: " + podName)
+	evictionInformation := misim.EvictionInformation{Pod: podName, Node: pod.Spec.NodeName}
+	c.storage.Progress.Publish(misim.StreamMessage{Type: misim.StreamEviction, Eviction: &evictionInformation})
+	return nil
+}
+
 func (c *PodController) updatePodChannel() {
 	processedPodCount := c.storage.Pods.FailedPodBuffer().Size() + c.storage.Pods.BindedPodBuffer().Size()
 	podsToBePlacedCount := c.storage.Pods.PodsToBePlaced().Size()
@@ -159,21 +262,30 @@ func (c *PodController) updatePodChannel() {
 			broadcaster := c.storage.Nodes.GetNodeUpscalingChannel()
 			nodeChannel := broadcaster.Subscribe()
 			defer broadcaster.CancelSubscription(nodeChannel)
-			var newNode v1.Node
 			klog.V(6).Info("Waiting for cluster-autoscaler upscaling")
-			newNode = <-nodeChannel
+			waitStart := time.Now()
+			newNode, ok := <-nodeChannel
+			if !ok {
+				klog.V(1).Info("Node upscaling broadcaster closed while waiting for cluster-autoscaler upscaling")
+				return
+			}
+			waitEnd := time.Now()
+			for _, failure := range c.storage.Pods.FailedPodBuffer().Items() {
+				c.storage.Timeline.RecordSpan(failure.Pod, timeline.ScaleUpWait, waitStart, waitEnd, map[string]string{"node": newNode.Name})
+			}
 			c.storage.Nodes.NewNodes().Put(newNode)
 			// TODO [Process Status Config map from Cluster Autoscaler]: read from status config map of cluster autoscaler to track status
 			c.storage.AdapterState.StoreClusterAutoscalingDone(true)
 			// Empty failed pod buffer, they should be scheduled now
 			c.storage.Pods.FailedPodBuffer().Clear()
 		} else {
-			c.storage.Pods.PodsUpdateChannel().Get() <- misim.PodsUpdateResponse{
+			// A round that was aborted already is not answered again
+			c.storage.Pods.PodsUpdateChannel().Send(misim.PodsUpdateResponse{
 				Failed:       c.storage.Pods.FailedPodBuffer().Items(),
 				Binded:       c.storage.Pods.BindedPodBuffer().Items(),
 				NewNodes:     c.storage.Nodes.NewNodes().Items(),
 				DeletedNodes: c.storage.Nodes.DeletedNodes().Items(),
-			}
+			})
 		}
 	}
 }
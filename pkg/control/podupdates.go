@@ -2,24 +2,70 @@ package control
 
 import (
 	"go-kube/pkg/misim"
+	"go-kube/pkg/results"
 	"go-kube/pkg/storage"
+
+	"k8s.io/klog/v2"
 )
 
 type PodUpdatesResource interface {
-	Post(misim.PodsUpdateRequest) misim.PodsUpdateResponse
+	Post(misim.PodsUpdateRequest) (misim.PodsUpdateResponse, error)
+	PostDelta(misim.PodsDeltaRequest) (misim.PodsUpdateResponse, error)
+	PostSync(misim.PodsUpdateRequest) (misim.PodsUpdateResponse, error)
 }
 
 type PodUpdatesResourceImpl struct {
-	storage *storage.StorageContainer
+	storage          *storage.StorageContainer
+	strictValidation bool
+	// Records the outcome of every round, nil if no results file is configured
+	results *results.Recorder
 }
 
-func (impl PodUpdatesResourceImpl) Post(u misim.PodsUpdateRequest) misim.PodsUpdateResponse {
+func (impl PodUpdatesResourceImpl) Post(u misim.PodsUpdateRequest) (misim.PodsUpdateResponse, error) {
+	if err := checkValidation("PodsUpdateRequest", misim.ValidatePodsUpdateRequest(u), impl.strictValidation); err != nil {
+		return misim.PodsUpdateResponse{}, err
+	}
 	controller := NewPodController(impl.storage)
-	return controller.UpdatePods(u.AllPods, u.Events, u.PodsToBePlaced, false)
+	response := controller.UpdatePods(u.AllPods, u.Events, u.PodsToBePlaced, false)
+	impl.recordRound(u.SimTime, u.PodsToBePlaced, response)
+	return response, nil
+}
+
+func (impl PodUpdatesResourceImpl) PostDelta(u misim.PodsDeltaRequest) (misim.PodsUpdateResponse, error) {
+	if err := checkValidation("PodsDeltaRequest", misim.ValidatePodsDeltaRequest(u), impl.strictValidation); err != nil {
+		return misim.PodsUpdateResponse{}, err
+	}
+	controller := NewPodController(impl.storage)
+	response, err := controller.UpdatePodsDelta(u)
+	if err != nil {
+		return response, err
+	}
+	impl.recordRound(u.SimTime, u.PodsToBePlaced, response)
+	return response, nil
+}
+
+// Like Post, but the watch events are derived from the difference between the stored and the passed pods
+func (impl PodUpdatesResourceImpl) PostSync(u misim.PodsUpdateRequest) (misim.PodsUpdateResponse, error) {
+	// Events are ignored in sync mode, so they are not validated
+	if err := checkValidation("PodsUpdateRequest", misim.ValidatePodsUpdateRequest(misim.PodsUpdateRequest{AllPods: u.AllPods, PodsToBePlaced: u.PodsToBePlaced}), impl.strictValidation); err != nil {
+		return misim.PodsUpdateResponse{}, err
+	}
+	controller := NewPodController(impl.storage)
+	if len(u.Events) > 0 {
+		klog.V(2).Infof("Pod-Sync: ignoring %d events sent by the simulation", len(u.Events))
+	}
+	response, err := controller.SyncPods(u.AllPods, u.PodsToBePlaced)
+	if err != nil {
+		return response, err
+	}
+	impl.recordRound(u.SimTime, u.PodsToBePlaced, response)
+	return response, nil
 }
 
-func NewPodUpdateResource(storage *storage.StorageContainer) PodUpdatesResourceImpl {
+func NewPodUpdateResource(storage *storage.StorageContainer, strictValidation bool, results *results.Recorder) PodUpdatesResourceImpl {
 	return PodUpdatesResourceImpl{
-		storage: storage,
+		storage:          storage,
+		strictValidation: strictValidation,
+		results:          results,
 	}
 }
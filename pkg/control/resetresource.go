@@ -0,0 +1,59 @@
+package control
+
+import (
+	"go-kube/pkg/admin"
+	"go-kube/pkg/storage"
+
+	"k8s.io/klog/v2"
+)
+
+type ResetResource interface {
+	Post() admin.ResetResponse
+}
+
+type ResetResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+// Clears all storages, so the next experiment can start without restarting the adapter.
+// Watch connections stay open, the components receive a DELETED event for every object.
+func (impl ResetResourceImpl) Post() admin.ResetResponse {
+	klog.V(1).Info("Resetting the adapter")
+	// Bindings and updates wait until the reset is done
+	impl.storage.Pods.BeginTransaction()
+	defer impl.storage.Pods.EndTransaction()
+	impl.storage.Nodes.BeginTransaction()
+	defer impl.storage.Nodes.EndTransaction()
+
+	// A pods update still waiting for the scheduler learns what happened to its pods before the buffers are cleared
+	controller := NewPodController(impl.storage)
+	if controller.AbortRound("the adapter was reset") {
+		klog.V(1).Info("Answered pending pods update because of the reset")
+	}
+	response := admin.ResetResponse{Deleted: map[string]int{
+		"pods":            impl.storage.Pods.Reset(),
+		"machines":        impl.storage.Machines.Reset(),
+		"machineSets":     impl.storage.MachineSets.Reset(),
+		"nodes":           impl.storage.Nodes.Reset(),
+		"namespaces":      impl.storage.Namespaces.Reset(),
+		"daemonSets":      impl.storage.DaemonSets.Reset(),
+		"priorityClasses": impl.storage.PriorityClasses.Reset(),
+		"configMaps":      impl.storage.ConfigMaps.Reset(),
+		"events":          impl.storage.Events.Reset(),
+	}}
+	impl.storage.StatusConfigMap.Reset()
+	impl.storage.PodIds.Reset()
+	impl.storage.MachineIds.Reset()
+	impl.storage.NodeIds.Reset()
+	impl.storage.ObjectIds.Reset()
+	impl.storage.AdapterState.Reset()
+	impl.storage.Timeline.Reset()
+	klog.V(2).Infof("Reset removed %v", response.Deleted)
+	return response
+}
+
+func NewResetResource(storage *storage.StorageContainer) ResetResourceImpl {
+	return ResetResourceImpl{
+		storage: storage,
+	}
+}
@@ -0,0 +1,50 @@
+package control
+
+import (
+	"go-kube/pkg/misim"
+	"go-kube/pkg/results"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// Appends the outcome of a round to the results file, if one is configured.
+// Failing to write the results does not fail the round.
+func (impl PodUpdatesResourceImpl) recordRound(simTime *float64, podsToBePlaced v1.PodList, response misim.PodsUpdateResponse) {
+	if impl.results == nil {
+		return
+	}
+	round := results.Round{
+		SimTime:                 simTime,
+		Time:                    time.Now(),
+		Placed:                  make([]string, len(podsToBePlaced.Items)),
+		Bound:                   response.Binded,
+		Failed:                  response.Failed,
+		NewNodes:                nodeNames(response.NewNodes),
+		DeletedNodes:            nodeNames(response.DeletedNodes),
+		MachineSetReplicas:      make(map[string]int32),
+		ClusterAutoscalerActive: impl.storage.AdapterState.IsClusterAutoscalerActive(),
+		ClusterAutoscalerStatus: impl.storage.StatusConfigMap.GetStatusConfigMap().Data["status"],
+	}
+	for i, pod := range podsToBePlaced.Items {
+		round.Placed[i] = pod.Name
+	}
+	machineSets, _ := impl.storage.MachineSets.GetMachineSets()
+	for _, machineSet := range machineSets.Items {
+		if machineSet.Spec.Replicas != nil {
+			round.MachineSetReplicas[machineSet.Name] = *machineSet.Spec.Replicas
+		}
+	}
+	if err := impl.results.Record(round); err != nil {
+		klog.V(1).ErrorS(err, "Unable to write the results of the round")
+	}
+}
+
+func nodeNames(nodes []v1.Node) []string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.Name
+	}
+	return names
+}
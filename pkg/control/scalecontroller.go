@@ -3,6 +3,8 @@ package control
 import (
 	"errors"
 	"fmt"
+	"go-kube/internal/metrics"
+	"go-kube/pkg/misim"
 	"go-kube/pkg/storage"
 	autoscaling "k8s.io/api/autoscaling/v1"
 	core "k8s.io/api/core/v1"
@@ -33,6 +35,7 @@ func (c ScaleController) ScaleMachineSet(machineSetName string, u autoscaling.Sc
 
 	// Check if we have to scale down
 	if scaleAmount < 0 {
+		metrics.MachineSetScaleOperations.WithLabelValues(c.storage.Cluster, machineSetName, "down").Inc()
 		// For downscaling, we first delete nodes then machines
 		scaledDownNodes, err := c.ScaleDownNodes(-int(scaleAmount))
 
@@ -43,6 +46,7 @@ func (c ScaleController) ScaleMachineSet(machineSetName string, u autoscaling.Sc
 		// Scale machines
 		c.ScaleDownMachines(machineSet, scaledDownNodes, -int(scaleAmount))
 	} else if scaleAmount > 0 {
+		metrics.MachineSetScaleOperations.WithLabelValues(c.storage.Cluster, machineSetName, "up").Inc()
 		addedMachines, err := c.ScaleUpMachines(machineSet, int(scaleAmount))
 
 		if err != nil {
@@ -60,13 +64,10 @@ func (c ScaleController) ScaleMachineSet(machineSetName string, u autoscaling.Sc
 func (c ScaleController) ScaleDownMachines(machineSet cluster.MachineSet, changedNodes []core.Node, amount int) {
 	// In case of downscaling we need to delete machines
 	for _, changedNode := range changedNodes {
-		allMachines, _ := c.storage.Machines.GetMachines()
-		var nodeMachine cluster.Machine
-		for _, machine := range allMachines.Items {
-			if machine.Status.NodeRef.Name == changedNode.Name {
-				nodeMachine = machine
-				break
-			}
+		nodeMachine, found := c.storage.Machines.GetMachineOfNode(changedNode.Name)
+		if !found {
+			klog.V(3).Infof("No machine found for deleted node %s", changedNode.Name)
+			continue
 		}
 
 		c.storage.Machines.DeleteMachine(nodeMachine.Name)
@@ -112,6 +113,8 @@ func (c ScaleController) ScaleUpMachines(machineSet cluster.MachineSet, amount i
 }
 
 func (c ScaleController) ScaleDownNodes(amount int) ([]core.Node, error) {
+	c.storage.Nodes.BeginTransaction()
+	defer c.storage.Nodes.EndTransaction()
 	// Find nodes that should be deleted to scale down
 	var nodesToDelete []core.Node
 	allNodes, _ := c.storage.Nodes.GetNodes()
@@ -136,6 +139,7 @@ func (c ScaleController) ScaleDownNodes(amount int) ([]core.Node, error) {
 
 		changedNodes = append(changedNodes, nodeToDelete)
 		c.storage.Nodes.DeleteNode(nodeToDelete.Name)
+		c.storage.Progress.Publish(misim.StreamMessage{Type: misim.StreamDeletedNode, Node: &nodeToDelete})
 
 		amount = amount - 1
 	}
@@ -171,8 +175,11 @@ func (c ScaleController) ScaleUpNodes(addedMachines []cluster.Machine) {
 		}
 
 	}
+	c.storage.Nodes.BeginTransaction()
+	defer c.storage.Nodes.EndTransaction()
 	for i := range newNodes {
 		c.storage.Nodes.AddNode(newNodes[i])
+		c.storage.Progress.Publish(misim.StreamMessage{Type: misim.StreamNewNode, Node: &newNodes[i]})
 	}
 }
 
@@ -0,0 +1,35 @@
+package control
+
+import (
+	"go-kube/pkg/storage"
+	"go-kube/pkg/timeline"
+
+	"k8s.io/klog/v2"
+)
+
+type TimelineResource interface {
+	GetChromeTrace() timeline.ChromeTrace
+	GetOtlp() timeline.OtlpTraces
+}
+
+type TimelineResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl TimelineResourceImpl) GetChromeTrace() timeline.ChromeTrace {
+	events := impl.storage.Timeline.GetEvents()
+	klog.V(3).Info("Exporting timeline with ", len(events), " events as Chrome trace")
+	return timeline.ToChromeTrace(events)
+}
+
+func (impl TimelineResourceImpl) GetOtlp() timeline.OtlpTraces {
+	events := impl.storage.Timeline.GetEvents()
+	klog.V(3).Info("Exporting timeline with ", len(events), " events as OTLP traces")
+	return timeline.ToOtlp(events)
+}
+
+func NewTimelineResource(storage *storage.StorageContainer) TimelineResourceImpl {
+	return TimelineResourceImpl{
+		storage: storage,
+	}
+}
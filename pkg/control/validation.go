@@ -0,0 +1,23 @@
+package control
+
+import (
+	"go-kube/pkg/misim"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// Logs the warnings of the validation and returns an Invalid error (422) if the request has to be rejected
+func checkValidation(kind string, result misim.ValidationResult, strict bool) error {
+	if !strict {
+		for _, warning := range result.Warnings {
+			klog.V(2).Infof("%s warning: %s", kind, warning.Error())
+		}
+	}
+	if errs := result.Rejecting(strict); len(errs) > 0 {
+		klog.V(1).Infof("Rejected %s with %d problems", kind, len(errs))
+		return apierrors.NewInvalid(schema.GroupKind{Group: "misim", Kind: kind}, "", errs)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package dashboard
+
+import (
+	_ "embed"
+)
+
+// Single page of the dashboard, it renders the states sent by the stream endpoint
+//
+//go:embed index.html
+var Page []byte
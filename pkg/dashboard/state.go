@@ -0,0 +1,51 @@
+package dashboard
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Snapshot of the cluster shown by the dashboard
+type State struct {
+	// Current scheduling round of the simulation
+	Round       int               `json:"round"`
+	Nodes       []NodeState       `json:"nodes"`
+	PendingPods []PendingPod      `json:"pendingPods"`
+	MachineSets []MachineSetState `json:"machineSets"`
+	// Most recent events, newest first
+	Events []EventState `json:"events"`
+}
+
+type NodeState struct {
+	Name string `json:"name"`
+	// Allocatable cpu, memory and pods of the node
+	Allocatable map[string]resource.Quantity `json:"allocatable"`
+	// Sum of the requests of the pods bound to the node
+	Requested map[string]resource.Quantity `json:"requested"`
+	Pods      []string                     `json:"pods"`
+}
+
+// Pod that is not bound to a node
+type PendingPod struct {
+	Name string `json:"name"`
+	// Message of the last failed scheduling attempt, if any
+	Message string `json:"message,omitempty"`
+}
+
+type MachineSetState struct {
+	Name string `json:"name"`
+	// Bounds of the cluster-autoscaler node group, nil if not annotated
+	MinReplicas *int  `json:"minReplicas,omitempty"`
+	MaxReplicas *int  `json:"maxReplicas,omitempty"`
+	Replicas    int32 `json:"replicas"`
+	Machines    int   `json:"machines"`
+}
+
+type EventState struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Reason  string    `json:"reason"`
+	Object  string    `json:"object"`
+	Message string    `json:"message"`
+}
@@ -0,0 +1,124 @@
+package faults
+
+import (
+	"go-kube/pkg/admin"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Faults injected into a single request
+type Decision struct {
+	Latency time.Duration
+	// Status code to answer with instead of handling the request, 0 for none
+	ErrorStatus int
+	// Whether to answer with 409 Conflict instead of handling the request
+	Conflict bool
+	// Number of events after which a watch is closed, 0 keeps it open
+	WatchDisconnectAfter int
+}
+
+// Decides the faults of the requests according to a fault config. The decisions are
+// drawn from a random source seeded by the config, so they are deterministic for the
+// same sequence of requests.
+type Injector struct {
+	config admin.FaultConfig
+
+	mu     sync.Mutex
+	random *rand.Rand
+}
+
+func NewInjector(config admin.FaultConfig) *Injector {
+	return &Injector{config: config, random: rand.New(rand.NewSource(config.Seed))}
+}
+
+func (i *Injector) Config() admin.FaultConfig {
+	return i.config
+}
+
+// Returns the faults of a request to the route template with the verb, WATCH for watches
+func (i *Injector) Decide(route string, verb string) Decision {
+	rule, found := i.matchingRule(route, verb)
+	if !found {
+		return Decision{}
+	}
+	// Every matching request draws the same numbers, so that one fault does not shift the others
+	i.mu.Lock()
+	jitter, errorDraw, conflictDraw := i.random.Float64(), i.random.Float64(), i.random.Float64()
+	i.mu.Unlock()
+
+	decision := Decision{
+		Latency:              rule.Latency.Duration + time.Duration(jitter*float64(rule.LatencyJitter.Duration)),
+		WatchDisconnectAfter: rule.WatchDisconnectAfter,
+	}
+	if errorDraw < rule.ErrorRate {
+		decision.ErrorStatus = rule.ErrorStatus
+		if decision.ErrorStatus == 0 {
+			decision.ErrorStatus = http.StatusInternalServerError
+		}
+	} else if (verb == http.MethodPut || verb == http.MethodPatch) && conflictDraw < rule.ConflictRate {
+		decision.Conflict = true
+	}
+	return decision
+}
+
+func (i *Injector) matchingRule(route string, verb string) (admin.FaultRule, bool) {
+	for _, rule := range i.config.Rules {
+		if matchesRoute(rule.Route, route) && matchesVerb(rule.Verbs, verb) {
+			return rule, true
+		}
+	}
+	return admin.FaultRule{}, false
+}
+
+func matchesRoute(pattern string, route string) bool {
+	if prefix, found := strings.CutSuffix(pattern, "*"); found {
+		return strings.HasPrefix(route, prefix)
+	}
+	return pattern == "" || pattern == route
+}
+
+func matchesVerb(verbs []string, verb string) bool {
+	if len(verbs) == 0 {
+		return true
+	}
+	for _, v := range verbs {
+		if strings.EqualFold(v, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func Validate(config admin.FaultConfig) field.ErrorList {
+	var errs field.ErrorList
+	for i, rule := range config.Rules {
+		path := field.NewPath("rules").Index(i)
+		if rule.Route != "" && !strings.HasPrefix(rule.Route, "/") {
+			errs = append(errs, field.Invalid(path.Child("route"), rule.Route, "must start with /"))
+		}
+		if rule.Latency.Duration < 0 {
+			errs = append(errs, field.Invalid(path.Child("latency"), rule.Latency.Duration.String(), "must not be negative"))
+		}
+		if rule.LatencyJitter.Duration < 0 {
+			errs = append(errs, field.Invalid(path.Child("latencyJitter"), rule.LatencyJitter.Duration.String(), "must not be negative"))
+		}
+		if rule.ErrorRate < 0 || rule.ErrorRate > 1 {
+			errs = append(errs, field.Invalid(path.Child("errorRate"), rule.ErrorRate, "must be between 0 and 1"))
+		}
+		if rule.ErrorStatus != 0 && (rule.ErrorStatus < 400 || rule.ErrorStatus > 599) {
+			errs = append(errs, field.Invalid(path.Child("errorStatus"), rule.ErrorStatus, "must be an error status between 400 and 599"))
+		}
+		if rule.ConflictRate < 0 || rule.ConflictRate > 1 {
+			errs = append(errs, field.Invalid(path.Child("conflictRate"), rule.ConflictRate, "must be between 0 and 1"))
+		}
+		if rule.WatchDisconnectAfter < 0 {
+			errs = append(errs, field.Invalid(path.Child("watchDisconnectAfter"), rule.WatchDisconnectAfter, "must not be negative"))
+		}
+	}
+	return errs
+}
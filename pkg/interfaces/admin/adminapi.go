@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"go-kube/pkg/control"
+	"go-kube/pkg/storage"
+)
+
+type AdminApi interface {
+	Reset() control.ResetResource
+	Bootstrap() control.BootstrapResource
+	Export() control.ExportResource
+	ApiUsage() control.ApiUsageResource
+	Faults() control.FaultsResource
+}
+
+type AdminApiImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl AdminApiImpl) Reset() control.ResetResource {
+	return control.NewResetResource(impl.storage)
+}
+
+func (impl AdminApiImpl) Bootstrap() control.BootstrapResource {
+	return control.NewBootstrapResource(impl.storage)
+}
+
+func (impl AdminApiImpl) Export() control.ExportResource {
+	return control.NewExportResource(impl.storage)
+}
+
+func (impl AdminApiImpl) ApiUsage() control.ApiUsageResource {
+	return control.NewApiUsageResource(impl.storage)
+}
+
+func (impl AdminApiImpl) Faults() control.FaultsResource {
+	return control.NewFaultsResource(impl.storage)
+}
+
+func NewAdminApi(storage *storage.StorageContainer) AdminApiImpl {
+	return AdminApiImpl{storage: storage}
+}
@@ -0,0 +1,64 @@
+package interfaces
+
+import (
+	"fmt"
+	"go-kube/internal/infrastructure"
+	"go-kube/pkg/config"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Returns a middleware serving the routes below /apis/{group} according to the configured mode of the group
+func apiGroupMiddleware(adapterConfig config.Config, pathPrefix string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			groupPath, found := strings.CutPrefix(strings.TrimPrefix(r.URL.Path, pathPrefix), "/apis/")
+			if !found {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// Group, version and the path of the resource
+			segments := strings.Split(groupPath, "/")
+			group := segments[0]
+			switch adapterConfig.APIGroupMode(group) {
+			case config.Disabled:
+				infrastructure.MarkUnsupported(r)
+				infrastructure.WriteError(w, apierrors.NewNotFound(schema.GroupResource{Resource: "apigroups"}, group))
+			case config.Stubbed:
+				if len(segments) <= 2 {
+					// Discovery of the group and its version
+					next.ServeHTTP(w, r)
+				} else if r.Method == http.MethodGet {
+					infrastructure.UnsupportedResource()(w, r)
+				} else {
+					infrastructure.MarkUnsupported(r)
+					infrastructure.WriteError(w, &apierrors.StatusError{ErrStatus: metav1.Status{
+						Status:  metav1.StatusFailure,
+						Code:    http.StatusMethodNotAllowed,
+						Reason:  metav1.StatusReasonMethodNotAllowed,
+						Message: fmt.Sprintf("the API group %s is stubbed, %s requests are not supported", group, r.Method),
+					}})
+				}
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// Removes the disabled groups from the discovery
+func servedAPIGroups(groups metav1.APIGroupList, adapterConfig config.Config) metav1.APIGroupList {
+	served := make([]metav1.APIGroup, 0, len(groups.Groups))
+	for _, group := range groups.Groups {
+		if adapterConfig.APIGroupMode(group.Name) != config.Disabled {
+			served = append(served, group)
+		}
+	}
+	groups.Groups = served
+	return groups
+}
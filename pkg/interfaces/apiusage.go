@@ -0,0 +1,52 @@
+package interfaces
+
+import (
+	"go-kube/internal/infrastructure"
+	"go-kube/internal/metrics"
+	apiadmin "go-kube/pkg/admin"
+	"go-kube/pkg/storage"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Returns a middleware recording every request by User-Agent, verb and route template.
+// Requests are recorded as soon as their status is known, so that watches show up while they are open.
+func apiUsageMiddleware(usage storage.ApiUsageStorage, pathPrefix string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := strings.TrimPrefix(r.URL.Path, pathPrefix)
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = strings.TrimPrefix(template, pathPrefix)
+				}
+			}
+			r, unsupported := infrastructure.TrackUnsupported(r)
+			record := func(status int) {
+				support := apiadmin.RouteSupported
+				if *unsupported {
+					support = apiadmin.RouteUnsupported
+				}
+				usage.RecordRequest(r.UserAgent(), metrics.Verb(r), route, support, status)
+			}
+			recorder := metrics.NewStatusRecorder(w, record)
+			next.ServeHTTP(recorder, r)
+			if !recorder.WroteHeader() {
+				record(recorder.Status())
+			}
+		})
+	}
+}
+
+// Returns a handler recording requests without route and answering them like the router does by default
+func unknownRouteHandler(usage storage.ApiUsageStorage, pathPrefix string, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		usage.RecordRequest(r.UserAgent(), metrics.Verb(r), strings.TrimPrefix(r.URL.Path, pathPrefix), apiadmin.RouteUnknown, status)
+		if status == http.StatusNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+	})
+}
@@ -0,0 +1,122 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-kube/internal/infrastructure"
+	"go-kube/internal/metrics"
+	"go-kube/pkg/audit"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// Paths served like by the Kubernetes API server, the simulator and administration endpoints are not audited
+var auditedPaths = []string{"/api", "/apis", "/version", "/openapi", "/healthz", "/livez", "/readyz"}
+
+func isAuditedPath(path string) bool {
+	for _, prefix := range auditedPaths {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns a handler logging the requests to next at the level the audit policy sets. It wraps the
+// router instead of being a middleware, so that requests without route are logged as well.
+func auditHandler(logger *audit.Logger, clusterId string, pathPrefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, pathPrefix)
+		if !isAuditedPath(path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		attributes := audit.NewRequestAttributes(r, path)
+		level, omitStages := logger.Policy().LevelFor(attributes)
+		if level == audit.LevelNone {
+			next.ServeHTTP(w, r)
+			return
+		}
+		event := logger.NewEvent(r, attributes, level, clusterId)
+		if level.Includes(audit.LevelRequest) && r.Body != nil {
+			body, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				infrastructure.WriteError(w, apierrors.NewBadRequest(err.Error()))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			// Protobuf requests are not logged with their body
+			if json.Valid(body) {
+				event.RequestObject = body
+			}
+		}
+		logStage := func(stage audit.Stage) {
+			for _, omitted := range omitStages {
+				if omitted == stage {
+					return
+				}
+			}
+			event.Stage = stage
+			event.StageTimestamp = metav1.NewMicroTime(time.Now())
+			if err := logger.Log(event); err != nil {
+				klog.V(1).ErrorS(err, "Unable to write audit event", "auditID", event.AuditID)
+			}
+		}
+		logStage(audit.StageRequestReceived)
+
+		longRunning := attributes.Verb == "watch" || r.Header.Get("Upgrade") != ""
+		recorder := metrics.NewStatusRecorder(w, func(status int) {
+			if longRunning {
+				event.ResponseStatus = &metav1.Status{Code: int32(status)}
+				logStage(audit.StageResponseStarted)
+			}
+		})
+		// Bodies of watches are never captured, error bodies always for their status
+		writer := &auditResponseWriter{StatusRecorder: recorder, capture: func() bool {
+			return !longRunning && (level.Includes(audit.LevelRequestResponse) || recorder.Status() >= 400)
+		}}
+		next.ServeHTTP(writer, r)
+
+		event.ResponseStatus = responseStatus(recorder.Status(), writer.body.Bytes())
+		if level.Includes(audit.LevelRequestResponse) && json.Valid(writer.body.Bytes()) {
+			event.ResponseObject = writer.body.Bytes()
+		}
+		logStage(audit.StageResponseComplete)
+	})
+}
+
+// Returns the status of an error response like the Kubernetes API server logs it, only the code for other responses
+func responseStatus(code int, body []byte) *metav1.Status {
+	status := &metav1.Status{Code: int32(code)}
+	if code >= 400 {
+		var errorStatus metav1.Status
+		if err := json.Unmarshal(body, &errorStatus); err == nil && errorStatus.Kind == "Status" {
+			status.Status, status.Message, status.Reason = errorStatus.Status, errorStatus.Message, errorStatus.Reason
+		} else {
+			status.Status = metav1.StatusFailure
+		}
+	}
+	return status
+}
+
+// Keeps a copy of the response body if capture returns true
+type auditResponseWriter struct {
+	*metrics.StatusRecorder
+	capture func() bool
+	body    bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	n, err := w.StatusRecorder.Write(data)
+	if w.capture() {
+		w.body.Write(data[:n])
+	}
+	return n, err
+}
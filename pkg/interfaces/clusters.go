@@ -0,0 +1,282 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"go-kube/internal/broadcast"
+	"go-kube/internal/infrastructure"
+	"go-kube/pkg/admin"
+	"go-kube/pkg/control"
+	"go-kube/pkg/results"
+	"go-kube/pkg/storage"
+	"go-kube/pkg/storage/inmemorystorage"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+)
+
+// Path prefix of the APIs of the virtual clusters, followed by the id of the cluster
+const clustersPathPrefix = "/clusters/"
+
+// Time the listener of a deleted virtual cluster waits for open requests
+const clusterShutdownTimeout = 5 * time.Second
+
+var clusterResource = schema.GroupResource{Group: "admin", Resource: "clusters"}
+
+// Virtual cluster with its own storages, served below its path prefix and optionally by its own listener
+type virtualCluster struct {
+	info    admin.ClusterInfo
+	storage *storage.StorageContainer
+	app     *AdapterApplication
+	// Own listener of the cluster, nil if it has none
+	server  *http.Server
+	results *results.Recorder
+	// Stops the broadcasters of the storages, nil for the default cluster
+	cancel context.CancelFunc
+}
+
+// Virtual clusters of the adapter. It dispatches requests below /clusters/{id} to the
+// application of the cluster and all other requests to the default cluster.
+type clusterRegistry struct {
+	ctx     context.Context
+	root    *AdapterApplication
+	options AdapterOptions
+
+	mu       sync.RWMutex
+	clusters map[string]*virtualCluster
+	// Set when the adapter shuts down, new requests are rejected from then on
+	closing bool
+}
+
+func newClusterRegistry(ctx context.Context, root *AdapterApplication, options AdapterOptions) *clusterRegistry {
+	return &clusterRegistry{
+		ctx:      ctx,
+		root:     root,
+		options:  options,
+		clusters: make(map[string]*virtualCluster),
+	}
+}
+
+// Makes the default cluster available below its path prefix as well
+func (r *clusterRegistry) addDefault(storageContainer *storage.StorageContainer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clusters[DefaultCluster] = &virtualCluster{
+		info:    admin.ClusterInfo{Id: DefaultCluster, Path: clustersPathPrefix + DefaultCluster, Created: time.Now()},
+		storage: storageContainer,
+		app:     newClusterApplication(DefaultCluster, clustersPathPrefix+DefaultCluster, storageContainer, r.options),
+	}
+}
+
+func (r *clusterRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	clusterId, found := clusterOfPath(req.URL.Path)
+	r.mu.RLock()
+	cluster, closing := r.clusters[clusterId], r.closing
+	r.mu.RUnlock()
+	// Health checks report the shutdown themselves
+	if closing && !isHealthPath(req.URL.Path) {
+		infrastructure.WriteError(w, apierrors.NewServiceUnavailable("the adapter is shutting down"))
+		return
+	}
+	if !found {
+		r.root.handler.ServeHTTP(w, req)
+		return
+	}
+	if cluster == nil {
+		infrastructure.WriteError(w, apierrors.NewNotFound(clusterResource, clusterId))
+		return
+	}
+	cluster.app.handler.ServeHTTP(w, req)
+}
+
+func isHealthPath(path string) bool {
+	return path == "/healthz" || path == "/livez" || path == "/readyz"
+}
+
+// Returns the id of the virtual cluster addressed by the path
+func clusterOfPath(path string) (string, bool) {
+	rest, found := strings.CutPrefix(path, clustersPathPrefix)
+	if !found {
+		return "", false
+	}
+	clusterId, _, _ := strings.Cut(rest, "/")
+	return clusterId, clusterId != ""
+}
+
+func (r *clusterRegistry) List() []admin.ClusterInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	infos := make([]admin.ClusterInfo, 0, len(r.clusters))
+	for _, cluster := range r.clusters {
+		infos = append(infos, cluster.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Id < infos[j].Id })
+	return infos
+}
+
+func (r *clusterRegistry) Get(clusterId string) (admin.ClusterInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cluster, found := r.clusters[clusterId]
+	if !found {
+		return admin.ClusterInfo{}, apierrors.NewNotFound(clusterResource, clusterId)
+	}
+	return cluster.info, nil
+}
+
+func (r *clusterRegistry) Create(request admin.ClusterRequest) (admin.ClusterInfo, error) {
+	if errs := validation.IsDNS1123Label(request.Id); len(errs) > 0 {
+		return admin.ClusterInfo{}, apierrors.NewBadRequest(fmt.Sprintf("invalid cluster id %q: %s", request.Id, strings.Join(errs, ", ")))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, found := r.clusters[request.Id]; found {
+		return admin.ClusterInfo{}, apierrors.NewAlreadyExists(clusterResource, request.Id)
+	}
+
+	cluster := &virtualCluster{
+		info: admin.ClusterInfo{
+			Id:            request.Id,
+			Path:          clustersPathPrefix + request.Id,
+			ListenAddress: request.ListenAddress,
+			ResultsFile:   request.ResultsFile,
+			Created:       time.Now(),
+		},
+	}
+	options := AdapterOptions{Config: r.options.Config, StrictValidation: r.options.StrictValidation}
+	// Requests are not authenticated, so files and addresses are limited to the ones of the config file
+	if request.ListenAddress != "" && !r.options.Config.IsClusterListenAddress(request.ListenAddress) {
+		return admin.ClusterInfo{}, apierrors.NewBadRequest(fmt.Sprintf("listen address %q is not one of clusterListenAddresses in the config file", request.ListenAddress))
+	}
+	if request.ResultsFile != "" {
+		path, err := r.options.Config.ResultsPath(request.ResultsFile)
+		if err != nil {
+			return admin.ClusterInfo{}, apierrors.NewBadRequest(err.Error())
+		}
+		format := results.JSONL
+		if request.ResultsFormat != "" {
+			format = results.Format(request.ResultsFormat)
+		}
+		recorder, err := results.Open(path, format)
+		if err != nil {
+			return admin.ClusterInfo{}, apierrors.NewBadRequest(err.Error())
+		}
+		cluster.results = recorder
+		options.Results = recorder
+	}
+	var listener net.Listener
+	if request.ListenAddress != "" {
+		var err error
+		// Listening before the cluster is created reports a used address to the caller
+		listener, err = net.Listen("tcp", request.ListenAddress)
+		if err != nil {
+			if cluster.results != nil {
+				cluster.results.Close()
+			}
+			return admin.ClusterInfo{}, apierrors.NewBadRequest(err.Error())
+		}
+	}
+
+	ctx, cancel := context.WithCancel(broadcast.WithCluster(r.ctx, request.Id))
+	storageContainer := inmemorystorage.NewStorageContainer(ctx, r.options.Config.Namespaces, r.options.Config.TimelineMaxEvents)
+	cluster.storage = &storageContainer
+	cluster.cancel = cancel
+	cluster.app = newClusterApplication(request.Id, cluster.info.Path, cluster.storage, options)
+	if listener != nil {
+		cluster.server = &http.Server{Handler: newClusterApplication(request.Id, "", cluster.storage, options).handler}
+		go func() {
+			if err := cluster.server.Serve(listener); err != http.ErrServerClosed {
+				klog.V(1).ErrorS(err, "Listener of virtual cluster failed", "cluster", request.Id)
+			}
+		}()
+	}
+	r.clusters[request.Id] = cluster
+	klog.V(1).Infof("Created virtual cluster %s at %s", request.Id, cluster.info.Path)
+	return cluster.info, nil
+}
+
+// Deletes the virtual cluster. Its storages are reset first, so that connected components
+// receive DELETED events and a pending pods update is answered, then its watches are closed.
+func (r *clusterRegistry) Delete(clusterId string) (admin.ClusterInfo, error) {
+	if clusterId == DefaultCluster {
+		return admin.ClusterInfo{}, apierrors.NewBadRequest("the default cluster cannot be deleted")
+	}
+	r.mu.Lock()
+	cluster, found := r.clusters[clusterId]
+	delete(r.clusters, clusterId)
+	r.mu.Unlock()
+	if !found {
+		return admin.ClusterInfo{}, apierrors.NewNotFound(clusterResource, clusterId)
+	}
+
+	// The reset answers the pending round before it clears the buffers
+	control.NewResetResource(cluster.storage).Post()
+	// Stopping the broadcasters ends the watches after the DELETED events are written
+	cluster.cancel()
+	if cluster.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterShutdownTimeout)
+		defer cancel()
+		if err := cluster.server.Shutdown(ctx); err != nil {
+			cluster.server.Close()
+		}
+	}
+	if cluster.results != nil {
+		cluster.results.Close()
+	}
+	klog.V(1).Infof("Deleted virtual cluster %s", clusterId)
+	return cluster.info, nil
+}
+
+// Shuts all virtual clusters down: new requests are rejected, pending pods updates are answered
+// with the passed reason, and stopStorages stops the broadcasters, which ends the watches and
+// streams. Then the own listeners of the clusters wait for their open requests until ctx is done.
+func (r *clusterRegistry) shutdown(ctx context.Context, reason string, stopStorages context.CancelFunc) {
+	r.mu.Lock()
+	r.closing = true
+	clusters := make([]*virtualCluster, 0, len(r.clusters))
+	for _, cluster := range r.clusters {
+		clusters = append(clusters, cluster)
+	}
+	r.mu.Unlock()
+
+	for _, cluster := range clusters {
+		// Within the pod transaction, so that no binding completes the round meanwhile
+		cluster.storage.Pods.BeginTransaction()
+		controller := control.NewPodController(cluster.storage)
+		if controller.AbortRound(reason) {
+			klog.V(1).Infof("Aborted pending round of virtual cluster %s", cluster.info.Id)
+		}
+		cluster.storage.Pods.EndTransaction()
+	}
+	// The storages of all virtual clusters are derived from the context stopStorages belongs to
+	stopStorages()
+	for _, cluster := range clusters {
+		if cluster.server != nil {
+			if err := cluster.server.Shutdown(ctx); err != nil {
+				klog.V(1).ErrorS(err, "Listener of virtual cluster did not shut down in time", "cluster", cluster.info.Id)
+				cluster.server.Close()
+			}
+		}
+	}
+}
+
+// Closes the results files of the virtual clusters, the file of the default cluster is owned by the caller
+func (r *clusterRegistry) closeResults() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, cluster := range r.clusters {
+		if cluster.results != nil {
+			if err := cluster.results.Close(); err != nil {
+				klog.V(1).ErrorS(err, "Unable to close results file", "cluster", cluster.info.Id)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-kube/internal/infrastructure"
+	"go-kube/pkg/storage"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Returns a middleware recording the components that use the Kubernetes API and their watches
+func componentMiddleware(components storage.ComponentStorage, pathPrefix string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiPath := strings.TrimPrefix(r.URL.Path, pathPrefix)
+			if apiPath != "/api" && apiPath != "/apis" && !strings.HasPrefix(apiPath, "/api/") && !strings.HasPrefix(apiPath, "/apis/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			userAgent := r.UserAgent()
+			components.Seen(userAgent)
+			if r.URL.Query().Get("watch") != "" {
+				// The resource is the last path segment of list requests, e.g. pods
+				done := components.WatchStarted(userAgent, path.Base(apiPath))
+				defer done()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Answers whether the required components are connected, with 503 if they are not.
+// The components are passed as repeated or comma-separated require parameters,
+// the optional wait parameter blocks up to the passed duration until they are.
+func (app *AdapterApplication) componentsReady(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var required []string
+	for _, value := range query["require"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				required = append(required, name)
+			}
+		}
+	}
+	var wait time.Duration
+	if value := query.Get("wait"); value != "" {
+		var err error
+		if wait, err = time.ParseDuration(value); err != nil || wait < 0 {
+			infrastructure.WriteError(w, apierrors.NewBadRequest(fmt.Sprintf("invalid wait duration %q", value)))
+			return
+		}
+	}
+	readiness := app.sim2.Components().Ready(r.Context(), required, wait)
+	w.Header().Set("Content-Type", "application/json")
+	if !readiness.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(readiness)
+}
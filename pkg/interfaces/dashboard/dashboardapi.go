@@ -0,0 +1,129 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-kube/internal/broadcast"
+	"go-kube/internal/infrastructure"
+	"go-kube/pkg/control"
+	"go-kube/pkg/dashboard"
+	"go-kube/pkg/storage"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Minimum interval between two states sent to the browser, changes in between are coalesced
+	streamUpdatePeriod = 500 * time.Millisecond
+	// Interval in which the state is sent even without changes, events have no broadcaster
+	streamRefreshPeriod = 5 * time.Second
+)
+
+type DashboardApi interface {
+	State() control.DashboardResource
+	Page() infrastructure.Endpoint
+	Stream() infrastructure.Endpoint
+}
+
+type DashboardApiImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl DashboardApiImpl) State() control.DashboardResource {
+	return control.NewDashboardResource(impl.storage)
+}
+
+func (impl DashboardApiImpl) Page() infrastructure.Endpoint {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(dashboard.Page)
+	}
+}
+
+// Sends the state as server-sent event whenever pods, nodes, machine sets or
+// the progress of the current round change
+func (impl DashboardApiImpl) Stream() infrastructure.Endpoint {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			infrastructure.WriteError(w, fmt.Errorf("streaming is not supported"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		klog.V(3).Infof("Dashboard connected (%s)", r.RemoteAddr)
+
+		ctx := r.Context()
+		changed := make(chan struct{}, 1)
+		_, podBroadcaster := impl.storage.Pods.GetPods()
+		_, nodeBroadcaster := impl.storage.Nodes.GetNodes()
+		_, machineSetBroadcaster := impl.storage.MachineSets.GetMachineSets()
+		go notifyChanges(ctx, podBroadcaster, changed)
+		go notifyChanges(ctx, nodeBroadcaster, changed)
+		go notifyChanges(ctx, machineSetBroadcaster, changed)
+		go notifyChanges(ctx, impl.storage.Progress.GetProgressBroadcaster(), changed)
+
+		update := time.NewTicker(streamUpdatePeriod)
+		defer update.Stop()
+		lastSent := time.Time{}
+		pending := true
+		for {
+			// The storages stop when the cluster is deleted or the adapter shuts down
+			if podBroadcaster.Stopped() {
+				break
+			}
+			if pending || time.Since(lastSent) >= streamRefreshPeriod {
+				data, err := json.Marshal(impl.State().GetState())
+				if err != nil {
+					klog.V(1).ErrorS(err, "Unable to encode dashboard state")
+					return
+				}
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+					break
+				}
+				flusher.Flush()
+				lastSent = time.Now()
+				pending = false
+			}
+			select {
+			case <-ctx.Done():
+				klog.V(3).Infof("Dashboard disconnected (%s)", r.RemoteAddr)
+				return
+			case <-changed:
+				pending = true
+				// Wait for the next tick, so a burst of changes results in a single update
+				<-update.C
+			case <-update.C:
+			}
+		}
+		klog.V(3).Infof("Dashboard disconnected (%s)", r.RemoteAddr)
+	}
+}
+
+// Signals every message of the broadcaster on changed until the context is done.
+// The dashboard does not need every message, so the signal never blocks the broadcaster.
+func notifyChanges[T any](ctx context.Context, server *broadcast.BroadcastServer[T], changed chan<- struct{}) {
+	for !server.Stopped() {
+		subscription := server.Subscribe()
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				server.CancelSubscription(subscription)
+				return
+			case _, open = <-subscription:
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}
+		server.CancelSubscription(subscription)
+	}
+}
+
+func NewDashboardApi(storage *storage.StorageContainer) DashboardApiImpl {
+	return DashboardApiImpl{storage: storage}
+}
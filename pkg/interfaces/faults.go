@@ -0,0 +1,90 @@
+package interfaces
+
+import (
+	"errors"
+	"go-kube/internal/infrastructure"
+	"go-kube/internal/metrics"
+	"go-kube/pkg/storage"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// Returns a middleware injecting the configured faults into the requests to the Kubernetes API
+func faultMiddleware(faults storage.FaultStorage, pathPrefix string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiPath := strings.TrimPrefix(r.URL.Path, pathPrefix)
+			if apiPath != "/api" && apiPath != "/apis" && !strings.HasPrefix(apiPath, "/api/") && !strings.HasPrefix(apiPath, "/apis/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			route := apiPath
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = strings.TrimPrefix(template, pathPrefix)
+				}
+			}
+			verb := metrics.Verb(r)
+			decision := faults.GetFaults().Decide(route, verb)
+			if decision.Latency > 0 {
+				timer := time.NewTimer(decision.Latency)
+				select {
+				case <-r.Context().Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+			group, resource, name := resourceOfPath(apiPath)
+			if decision.ErrorStatus != 0 {
+				klog.V(4).Infof("Injected %d into %s %s", decision.ErrorStatus, verb, apiPath)
+				infrastructure.WriteError(w, apierrors.NewGenericServerResponse(decision.ErrorStatus, r.Method,
+					schema.GroupResource{Group: group, Resource: resource}, name, "injected fault", 0, true))
+				return
+			}
+			if decision.Conflict {
+				klog.V(4).Infof("Injected conflict into %s %s", verb, apiPath)
+				infrastructure.WriteError(w, apierrors.NewConflict(schema.GroupResource{Group: group, Resource: resource}, name,
+					errors.New("the object has been modified; please apply your changes to the latest version and try again")))
+				return
+			}
+			if decision.WatchDisconnectAfter > 0 {
+				r = infrastructure.WithWatchEventLimit(r, decision.WatchDisconnectAfter)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Returns group, resource and name of an object path like /apis/{group}/{version}/namespaces/{namespace}/{resource}/{name}.
+// For subresources, e.g. .../pods/{name}/binding, the resource of the object is returned.
+func resourceOfPath(apiPath string) (string, string, string) {
+	segments := strings.Split(strings.Trim(apiPath, "/"), "/")
+	group := ""
+	if segments[0] == "apis" && len(segments) > 1 {
+		group = segments[1]
+		segments = segments[1:]
+	}
+	// Skip api or group and the version
+	if len(segments) < 2 {
+		return group, "", ""
+	}
+	segments = segments[2:]
+	if len(segments) > 2 && segments[0] == "namespaces" {
+		segments = segments[2:]
+	}
+	switch len(segments) {
+	case 0:
+		return group, "", ""
+	case 1:
+		return group, segments[0], ""
+	default:
+		return group, segments[0], segments[1]
+	}
+}
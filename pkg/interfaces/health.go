@@ -0,0 +1,58 @@
+package interfaces
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Named check of a health endpoint, returns nil if healthy
+type healthCheck struct {
+	name  string
+	check func() error
+}
+
+func (r *clusterRegistry) healthChecks(includeShutdown bool) []healthCheck {
+	checks := []healthCheck{{name: "ping", check: func() error { return nil }}}
+	if includeShutdown {
+		// The process is still alive while it shuts down, but no longer serves requests
+		checks = append(checks, healthCheck{name: "shutdown", check: func() error {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			if r.closing {
+				return fmt.Errorf("the adapter is shutting down")
+			}
+			return nil
+		}})
+	}
+	return checks
+}
+
+// Serves a health endpoint like the Kubernetes API server: "ok" if all checks pass,
+// the result of every check with the verbose parameter, and status 500 if a check fails
+func (r *clusterRegistry) healthHandler(endpoint string, includeShutdown bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		var output strings.Builder
+		failed := false
+		for _, check := range r.healthChecks(includeShutdown) {
+			if err := check.check(); err != nil {
+				failed = true
+				fmt.Fprintf(&output, "[-]%s failed: %v\n", check.name, err)
+			} else {
+				fmt.Fprintf(&output, "[+]%s ok\n", check.name)
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if failed {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "%s%s check failed\n", output.String(), endpoint)
+			return
+		}
+		if _, verbose := req.URL.Query()["verbose"]; verbose {
+			fmt.Fprintf(w, "%s%s check passed\n", output.String(), endpoint)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}
+}
@@ -0,0 +1,24 @@
+package configmaps
+
+import (
+	"go-kube/internal/broadcast"
+	"go-kube/pkg/storage"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ConfigMapsResource interface {
+	Get() (v1.ConfigMapList, *broadcast.BroadcastServer[metav1.WatchEvent])
+}
+
+type ConfigMapsResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl ConfigMapsResourceImpl) Get() (v1.ConfigMapList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
+	return impl.storage.ConfigMaps.GetConfigMaps()
+}
+
+func NewConfigMapsResource(storage *storage.StorageContainer) ConfigMapsResourceImpl {
+	return ConfigMapsResourceImpl{storage: storage}
+}
@@ -0,0 +1,33 @@
+package configmap
+
+import (
+	"go-kube/pkg/storage"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+type ConfigmapResource interface {
+	Get() (core.ConfigMap, error)
+}
+
+type ConfigmapResourceImpl struct {
+	namespaceName string
+	name          string
+	storage       *storage.StorageContainer
+}
+
+func (impl ConfigmapResourceImpl) Get() (core.ConfigMap, error) {
+	configMap, found := impl.storage.ConfigMaps.GetConfigMap(impl.namespaceName, impl.name)
+	if !found {
+		return core.ConfigMap{}, apierrors.NewNotFound(core.Resource("configmaps"), impl.name)
+	}
+	return configMap, nil
+}
+
+func NewConfigmapResource(namespace string, name string, storage *storage.StorageContainer) ConfigmapResourceImpl {
+	return ConfigmapResourceImpl{
+		namespaceName: namespace,
+		name:          name,
+		storage:       storage,
+	}
+}
@@ -2,11 +2,13 @@ package configmaps
 
 import (
 	clusterautoscalerstatus "go-kube/pkg/interfaces/kubeapi/api/v1/namespaces/namespace/configmaps/cluster-autoscaler-status"
+	"go-kube/pkg/interfaces/kubeapi/api/v1/namespaces/namespace/configmaps/configmap"
 	"go-kube/pkg/storage"
 )
 
 type ConfigmapsResource interface {
 	ClusterAutoscalerStatus() clusterautoscalerstatus.ClusterAutoscalerStatusResource
+	Configmap(name string) configmap.ConfigmapResource
 }
 
 type ConfigmapsResourceImpl struct {
@@ -18,6 +20,10 @@ func (impl ConfigmapsResourceImpl) ClusterAutoscalerStatus() clusterautoscalerst
 	return clusterautoscalerstatus.NewClusterAutoscalerStatusResource(impl.namespaceName, impl.storage)
 }
 
+func (impl ConfigmapsResourceImpl) Configmap(name string) configmap.ConfigmapResource {
+	return configmap.NewConfigmapResource(impl.namespaceName, name, impl.storage)
+}
+
 func NewConfigmapsResource(name string, storage *storage.StorageContainer) ConfigmapsResourceImpl {
 	return ConfigmapsResourceImpl{
 		namespaceName: name,
@@ -0,0 +1,31 @@
+package eviction
+
+import (
+	"go-kube/pkg/control"
+	"go-kube/pkg/storage"
+	policy "k8s.io/api/policy/v1"
+)
+
+type EvictionResource interface {
+	Post(policy.Eviction) (policy.Eviction, error)
+}
+
+type EvictionResourceImpl struct {
+	podName string
+	storage *storage.StorageContainer
+}
+
+func (impl EvictionResourceImpl) Post(eviction policy.Eviction) (policy.Eviction, error) {
+	controller := control.NewPodController(impl.storage)
+	if err := controller.EvictPod(impl.podName); err != nil {
+		return policy.Eviction{}, err
+	}
+	return eviction, nil
+}
+
+func NewEvictionResource(podName string, storage *storage.StorageContainer) EvictionResourceImpl {
+	return EvictionResourceImpl{
+		podName: podName,
+		storage: storage,
+	}
+}
@@ -2,6 +2,7 @@ package pod
 
 import (
 	"go-kube/pkg/interfaces/kubeapi/api/v1/pods/pod/binding"
+	"go-kube/pkg/interfaces/kubeapi/api/v1/pods/pod/eviction"
 	"go-kube/pkg/interfaces/kubeapi/api/v1/pods/pod/status"
 	"go-kube/pkg/storage"
 )
@@ -9,6 +10,7 @@ import (
 type PodResource interface {
 	Status() status.StatusResource
 	Binding() binding.BindingResource
+	Eviction() eviction.EvictionResource
 }
 
 type PodResourceImpl struct {
@@ -24,6 +26,10 @@ func (impl PodResourceImpl) Binding() binding.BindingResource {
 	return binding.NewBindingResource(impl.podName, impl.storage)
 }
 
+func (impl PodResourceImpl) Eviction() eviction.EvictionResource {
+	return eviction.NewEvictionResource(impl.podName, impl.storage)
+}
+
 func NewPodResource(podName string, storage *storage.StorageContainer) PodResourceImpl {
 	return PodResourceImpl{
 		podName: podName,
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"go-kube/pkg/interfaces/kubeapi/api/v1/configmaps"
 	"go-kube/pkg/interfaces/kubeapi/api/v1/namespaces"
 	"go-kube/pkg/interfaces/kubeapi/api/v1/nodes"
 	"go-kube/pkg/interfaces/kubeapi/api/v1/pods"
@@ -13,6 +14,7 @@ type V1Resource interface {
 	Nodes() nodes.NodesResource
 	Pods() pods.PodsResource
 	Namespaces() namespaces.NamespacesResource
+	ConfigMaps() configmaps.ConfigMapsResource
 }
 
 type V1ResourceImpl struct {
@@ -31,10 +33,21 @@ func (impl V1ResourceImpl) Namespaces() namespaces.NamespacesResource {
 	return namespaces.NewNamespacesResource(impl.storage)
 }
 
+func (impl V1ResourceImpl) ConfigMaps() configmaps.ConfigMapsResource {
+	return configmaps.NewConfigMapsResource(impl.storage)
+}
+
 func (impl V1ResourceImpl) Get() metav1.APIResourceList {
 	return metav1.APIResourceList{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "APIResourceList"},
 		GroupVersion: "v1",
 		APIResources: []metav1.APIResource{
+			{
+				Name:         "configmaps",
+				SingularName: "",
+				Namespaced:   true,
+				Kind:         "ConfigMap",
+				Verbs:        []string{"get", "list", "watch"},
+			},
 			{
 				Name:         "namespaces",
 				SingularName: "",
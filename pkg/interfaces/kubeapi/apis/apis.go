@@ -5,6 +5,7 @@ import (
 	"go-kube/pkg/interfaces/kubeapi/apis/autoscaling"
 	"go-kube/pkg/interfaces/kubeapi/apis/cluster"
 	"go-kube/pkg/interfaces/kubeapi/apis/events"
+	"go-kube/pkg/interfaces/kubeapi/apis/scheduling"
 	"go-kube/pkg/storage"
 
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,6 +17,7 @@ type ApisResource interface {
 	Autoscaling() autoscaling.AutoscalingResource
 	Cluster() cluster.ClusterResource
 	Events() events.EventsResource
+	Scheduling() scheduling.SchedulingResource
 }
 
 type ApisResourceImpl struct {
@@ -51,6 +53,19 @@ func (api ApisResourceImpl) Get() meta.APIGroupList {
 					Version:      "v1",
 				},
 			},
+			{
+				Name: "scheduling.k8s.io",
+				Versions: []meta.GroupVersionForDiscovery{
+					{
+						GroupVersion: "scheduling.k8s.io/v1",
+						Version:      "v1",
+					},
+				},
+				PreferredVersion: meta.GroupVersionForDiscovery{
+					GroupVersion: "scheduling.k8s.io/v1",
+					Version:      "v1",
+				},
+			},
 		},
 	}
 }
@@ -71,6 +86,10 @@ func (api ApisResourceImpl) Events() events.EventsResource {
 	return events.NewEventsResource(api.storage)
 }
 
+func (api ApisResourceImpl) Scheduling() scheduling.SchedulingResource {
+	return scheduling.NewSchedulingResource(api.storage)
+}
+
 func NewApisResource(storage *storage.StorageContainer) ApisResource {
 	return ApisResourceImpl{
 		storage: storage,
@@ -0,0 +1,24 @@
+package scheduling
+
+import (
+	v1 "go-kube/pkg/interfaces/kubeapi/apis/scheduling/v1"
+	"go-kube/pkg/storage"
+)
+
+type SchedulingResource interface {
+	V1() v1.V1Resource
+}
+
+type SchedulingResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl SchedulingResourceImpl) V1() v1.V1Resource {
+	return v1.NewV1Resource(impl.storage)
+}
+
+func NewSchedulingResource(storage *storage.StorageContainer) SchedulingResource {
+	return SchedulingResourceImpl{
+		storage: storage,
+	}
+}
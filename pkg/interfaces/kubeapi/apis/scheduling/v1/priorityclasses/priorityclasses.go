@@ -0,0 +1,24 @@
+package priorityclasses
+
+import (
+	"go-kube/internal/broadcast"
+	"go-kube/pkg/storage"
+	v1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type PriorityClassesResource interface {
+	Get() (v1.PriorityClassList, *broadcast.BroadcastServer[metav1.WatchEvent])
+}
+
+type PriorityClassesResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl PriorityClassesResourceImpl) Get() (v1.PriorityClassList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
+	return impl.storage.PriorityClasses.GetPriorityClasses()
+}
+
+func NewPriorityClassesResource(storage *storage.StorageContainer) PriorityClassesResourceImpl {
+	return PriorityClassesResourceImpl{storage: storage}
+}
@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"go-kube/pkg/interfaces/kubeapi/apis/scheduling/v1/priorityclasses"
+	"go-kube/pkg/storage"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type V1Resource interface {
+	Get() metav1.APIResourceList
+	PriorityClasses() priorityclasses.PriorityClassesResource
+}
+
+type V1ResourceImpl struct {
+	storage *storage.StorageContainer
+}
+
+func (impl V1ResourceImpl) Get() metav1.APIResourceList {
+	return metav1.APIResourceList{TypeMeta: metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: "scheduling.k8s.io/v1",
+		APIResources: []metav1.APIResource{
+			{
+				Name:         "priorityclasses",
+				SingularName: "",
+				Namespaced:   false,
+				Kind:         "PriorityClass",
+				Verbs:        []string{"get", "list", "watch"},
+				ShortNames:   []string{"pc"},
+			},
+		},
+	}
+}
+
+func (impl V1ResourceImpl) PriorityClasses() priorityclasses.PriorityClassesResource {
+	return priorityclasses.NewPriorityClassesResource(impl.storage)
+}
+
+func NewV1Resource(storage *storage.StorageContainer) V1Resource {
+	return V1ResourceImpl{storage: storage}
+}
@@ -1,17 +1,35 @@
 package interfaces
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-kube/internal/broadcast"
 	"go-kube/internal/infrastructure"
+	"go-kube/internal/metrics"
+	apiadmin "go-kube/pkg/admin"
+	"go-kube/pkg/audit"
+	"go-kube/pkg/bootstrap"
+	"go-kube/pkg/config"
+	"go-kube/pkg/interfaces/admin"
+	"go-kube/pkg/interfaces/dashboard"
 	"go-kube/pkg/interfaces/kubeapi"
 	"go-kube/pkg/interfaces/simulation"
+	"go-kube/pkg/misim"
+	"go-kube/pkg/results"
 	"go-kube/pkg/storage"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	autoscaling "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
 	eventsv1 "k8s.io/api/events/v1"
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
@@ -20,63 +38,255 @@ import (
 	cluster "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
+// Id of the virtual cluster served at the root paths, it exists as long as the adapter runs
+const DefaultCluster = "default"
+
+// Time open requests get to finish when the adapter shuts down
+const shutdownTimeout = 10 * time.Second
+
+// Serves the API of one virtual cluster
 type AdapterApplication struct {
-	router *mux.Router
-	kube2  kubeapi.KubeApi
-	sim2   simulation.SimulationApi
+	// Routes of the cluster, a subrouter of handler if the cluster is addressed by a path prefix
+	router  *mux.Router
+	handler http.Handler
+	kube2   kubeapi.KubeApi
+	sim2    simulation.SimulationApi
+	board   dashboard.DashboardApi
+	admin   admin.AdminApi
+	config  config.Config
+	// Virtual clusters, only set for the application serving the root paths
+	clusters *clusterRegistry
 }
 
-func NewAdapterApplication(storageContainer *storage.StorageContainer) *AdapterApplication {
-	var router = mux.NewRouter().StrictSlash(true)
-	return &AdapterApplication{
-		router: router,
-		kube2:  kubeapi.NewKubeApi(storageContainer),
-		sim2:   simulation.NewSimulationApi(storageContainer),
-	}
+// Settings of the adapter given on the command line and in the config file
+type AdapterOptions struct {
+	Config config.Config
+	// Reject simulator requests with validation warnings, not only with errors
+	StrictValidation bool
+	// Appends the outcome of every simulator round to a results file, nil if disabled
+	Results *results.Recorder
+	// Logs the requests to the Kubernetes API of all virtual clusters, nil if disabled
+	Audit *audit.Logger
+}
+
+// Creates the application serving the default cluster with the passed storages at the root paths.
+// Further virtual clusters are created at runtime and get storages derived from ctx.
+func NewAdapterApplication(ctx context.Context, storageContainer *storage.StorageContainer, options AdapterOptions) *AdapterApplication {
+	app := newClusterApplication(DefaultCluster, "", storageContainer, options)
+	app.clusters = newClusterRegistry(ctx, app, options)
+	app.clusters.addDefault(storageContainer)
+	app.registerAdapterRoutes()
+	return app
 }
 
-func (app *AdapterApplication) Start() {
+// Creates the application of a virtual cluster, its routes are registered below pathPrefix
+func newClusterApplication(clusterId string, pathPrefix string, storageContainer *storage.StorageContainer, options AdapterOptions) *AdapterApplication {
+	var root = mux.NewRouter().StrictSlash(true)
+	var router = root
+	if pathPrefix != "" {
+		router = root.PathPrefix(pathPrefix).Subrouter()
+	}
+	router.Use(metrics.Middleware(clusterId, pathPrefix))
+	// Outside of the API group middleware, which answers the requests to stubbed groups
+	router.Use(apiUsageMiddleware(storageContainer.ApiUsage, pathPrefix))
+	router.Use(faultMiddleware(storageContainer.Faults, pathPrefix))
+	router.Use(apiGroupMiddleware(options.Config, pathPrefix))
+	router.Use(componentMiddleware(storageContainer.Components, pathPrefix))
+	// Requests without route are recorded as well, they show what the adapter lacks
+	root.NotFoundHandler = unknownRouteHandler(storageContainer.ApiUsage, pathPrefix, http.StatusNotFound)
+	root.MethodNotAllowedHandler = unknownRouteHandler(storageContainer.ApiUsage, pathPrefix, http.StatusMethodNotAllowed)
+	var handler http.Handler = root
+	if options.Audit != nil {
+		handler = auditHandler(options.Audit, clusterId, pathPrefix, root)
+	}
+	app := &AdapterApplication{
+		router:  router,
+		handler: handler,
+		kube2:   kubeapi.NewKubeApi(storageContainer),
+		sim2:    simulation.NewSimulationApi(storageContainer, options.StrictValidation, options.Results),
+		board:   dashboard.NewDashboardApi(storageContainer),
+		admin:   admin.NewAdminApi(storageContainer),
+		config:  options.Config,
+	}
 	app.registerRoutes()
-	var port = "8000"
-	klog.V(1).Info("Starting adapter on port ", port)
-	err := http.ListenAndServe(":"+port, app.router)
+	return app
+}
+
+// Serves the adapter until ctx is done and shuts it down gracefully afterwards. stopStorages
+// stops the broadcasters of the storages of all virtual clusters. Returns an error if the
+// adapter could not listen or open requests did not finish within the shutdown timeout.
+func (app *AdapterApplication) Start(ctx context.Context, stopStorages context.CancelFunc) error {
+	var address = app.clusters.options.Config.ListenAddress
+	// The registry passes requests of other virtual clusters on to their applications
+	server := &http.Server{Addr: address, Handler: app.clusters}
+	served := make(chan error, 1)
+	go func() {
+		if tls := app.clusters.options.Config.TLS; tls != nil {
+			klog.V(1).Info("Starting adapter with TLS on ", address)
+			served <- server.ListenAndServeTLS(tls.CertFile, tls.KeyFile)
+		} else {
+			klog.V(1).Info("Starting adapter on ", address)
+			served <- server.ListenAndServe()
+		}
+	}()
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	klog.V(1).Info("Shutting down adapter")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// Stops listening at once, then waits for the open requests that the registry ends
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(shutdownCtx)
+	}()
+	app.clusters.shutdown(shutdownCtx, "the adapter is shutting down", stopStorages)
+	err := <-shutdown
 	if err != nil {
-		klog.V(1).ErrorS(err, "Error when calling http.ListenAndServe, error is: %v", err)
+		server.Close()
+		err = fmt.Errorf("open requests did not finish within %s: %w", shutdownTimeout, err)
+	}
+	app.clusters.closeResults()
+	klog.V(1).Info("Adapter stopped")
+	return err
+}
+
+func (app *AdapterApplication) registerSimulatorRoutes() {
+	// Versioned simulator API
+	// Sync mode has to be registered first, as the plain routes match regardless of the query
+	sim := app.router.PathPrefix(misim.ApiPrefix).Subrouter()
+	sim.HandleFunc("/updateNodes", infrastructure.HandleRequestWithJSONBodyAndError(app.sim2.NodeUpdates().PostSync)).Methods("POST").Queries("mode", "sync")
+	sim.HandleFunc("/updatePods", infrastructure.HandleRequestWithJSONBodyAndError(app.sim2.PodUpdates().PostSync)).Methods("POST").Queries("mode", "sync")
+	sim.HandleFunc("/updateNodes", infrastructure.HandleRequestWithJSONBodyAndError(app.sim2.NodeUpdates().Post)).Methods("POST")
+	sim.HandleFunc("/updatePods", infrastructure.HandleRequestWithJSONBodyAndError(app.sim2.PodUpdates().Post)).Methods("POST")
+	sim.HandleFunc("/updateNodesDelta", infrastructure.HandleRequestWithJSONBodyAndError(app.sim2.NodeUpdates().PostDelta)).Methods("POST")
+	sim.HandleFunc("/updatePodsDelta", infrastructure.HandleRequestWithJSONBodyAndError(app.sim2.PodUpdates().PostDelta)).Methods("POST")
+	sim.HandleFunc("/stream", app.sim2.Stream()).Methods("GET")
+	sim.HandleFunc("/eventsApiEvents", app.eventsApiEvents).Methods("GET")
+	sim.HandleFunc("/coreApiEvents", app.coreApiEvents).Methods("GET")
+	sim.HandleFunc("/timeline/chrome", infrastructure.HandleJSONRequest(app.sim2.Timeline().GetChromeTrace)).Methods("GET")
+	sim.HandleFunc("/timeline/otlp", infrastructure.HandleJSONRequest(app.sim2.Timeline().GetOtlp)).Methods("GET")
+	sim.HandleFunc("/components", infrastructure.HandleJSONRequest(app.sim2.Components().Get)).Methods("GET")
+	sim.HandleFunc("/components/ready", app.componentsReady).Methods("GET")
+	sim.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(misim.OpenApiDocument)
+	}).Methods("GET")
+
+	// Unversioned simulator API with legacy JSON shapes
+	app.router.HandleFunc("/updateNodes", infrastructure.HandleRequestWithJSONBodyAndError(func(u misim.NodeUpdateRequest) (misim.LegacyNodeUpdateResponse, error) {
+		response, err := app.sim2.NodeUpdates().PostSync(u)
+		return misim.ToLegacyNodeUpdateResponse(response), err
+	})).Methods("POST").Queries("mode", "sync")
+	app.router.HandleFunc("/updatePods", infrastructure.HandleRequestWithJSONBodyAndError(func(u misim.PodsUpdateRequest) (misim.LegacyPodsUpdateResponse, error) {
+		response, err := app.sim2.PodUpdates().PostSync(u)
+		return misim.ToLegacyPodsUpdateResponse(response), err
+	})).Methods("POST").Queries("mode", "sync")
+	app.router.HandleFunc("/updateNodes", infrastructure.HandleRequestWithJSONBodyAndError(func(u misim.NodeUpdateRequest) (misim.LegacyNodeUpdateResponse, error) {
+		response, err := app.sim2.NodeUpdates().Post(u)
+		return misim.ToLegacyNodeUpdateResponse(response), err
+	})).Methods("POST")
+	app.router.HandleFunc("/updatePods", infrastructure.HandleRequestWithJSONBodyAndError(func(u misim.PodsUpdateRequest) (misim.LegacyPodsUpdateResponse, error) {
+		response, err := app.sim2.PodUpdates().Post(u)
+		return misim.ToLegacyPodsUpdateResponse(response), err
+	})).Methods("POST")
+	app.router.HandleFunc("/updateNodesDelta", infrastructure.HandleRequestWithJSONBodyAndError(func(u misim.NodeDeltaRequest) (misim.LegacyNodeUpdateResponse, error) {
+		response, err := app.sim2.NodeUpdates().PostDelta(u)
+		return misim.ToLegacyNodeUpdateResponse(response), err
+	})).Methods("POST")
+	app.router.HandleFunc("/updatePodsDelta", infrastructure.HandleRequestWithJSONBodyAndError(func(u misim.PodsDeltaRequest) (misim.LegacyPodsUpdateResponse, error) {
+		response, err := app.sim2.PodUpdates().PostDelta(u)
+		return misim.ToLegacyPodsUpdateResponse(response), err
+	})).Methods("POST")
+	app.router.HandleFunc("/stream", app.sim2.Stream()).Methods("GET")
+	app.router.HandleFunc("/getEventsApiEvents", app.eventsApiEvents).Methods("GET")
+	app.router.HandleFunc("/getCoreApiEvents", app.coreApiEvents).Methods("GET")
+}
+
+func (app *AdapterApplication) eventsApiEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	eventList := app.sim2.Events().GetEventsApiEvents()
+	eventsv1GV := eventsv1.SchemeGroupVersion
+	eventsV1Codec := scheme.Codecs.CodecForVersions(scheme.Codecs.LegacyCodec(eventsv1GV), scheme.Codecs.UniversalDecoder(eventsv1GV), eventsv1GV, eventsv1GV)
+	encodedEventList, err := runtime.Encode(eventsV1Codec, &eventList)
+	if err != nil {
+		klog.V(1).ErrorS(err, "There was an error encoding the events. err = ", err)
+		w.WriteHeader(500)
+		return
+	}
+	w.Write(encodedEventList)
+}
+
+func (app *AdapterApplication) coreApiEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	eventList := app.sim2.Events().GetCoreApiEvents()
+	corev1GV := v1.SchemeGroupVersion
+	coreV1Codec := scheme.Codecs.CodecForVersions(scheme.Codecs.LegacyCodec(corev1GV), scheme.Codecs.UniversalDecoder(corev1GV), corev1GV, corev1GV)
+	encodedEventList, err := runtime.Encode(coreV1Codec, &eventList)
+	if err != nil {
+		klog.V(1).ErrorS(err, "There was an error encoding the events. err = ", err)
+		w.WriteHeader(500)
+		return
+	}
+	w.Write(encodedEventList)
+}
+
+func (app *AdapterApplication) exportManifests(w http.ResponseWriter, r *http.Request) {
+	// Encoded completely before writing, so that an error can still be reported as status
+	var manifests bytes.Buffer
+	if err := bootstrap.WriteYAML(&manifests, app.admin.Export().Get()); err != nil {
+		klog.V(1).ErrorS(err, "There was an error encoding the exported manifests")
+		infrastructure.WriteError(w, err)
 		return
 	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(manifests.Bytes())
+}
+
+// Registers the routes that exist once per adapter process, not per virtual cluster
+func (app *AdapterApplication) registerAdapterRoutes() {
+	// Health checks
+	app.router.HandleFunc("/healthz", app.clusters.healthHandler("healthz", true)).Methods("GET")
+	app.router.HandleFunc("/livez", app.clusters.healthHandler("livez", false)).Methods("GET")
+	app.router.HandleFunc("/readyz", app.clusters.healthHandler("readyz", true)).Methods("GET")
+
+	// Debugging
+	app.router.HandleFunc("/debug/broadcasters", infrastructure.HandleJSONRequest(broadcast.AllStats)).Methods("GET")
+	app.router.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})).Methods("GET")
+
+	// Virtual clusters
+	app.router.HandleFunc("/admin/clusters", infrastructure.HandleJSONRequest(app.clusters.List)).Methods("GET")
+	app.router.HandleFunc("/admin/clusters", infrastructure.HandleRequestWithJSONBodyAndError(app.clusters.Create)).Methods("POST")
+	app.router.HandleFunc("/admin/clusters/{clusterId}", infrastructure.HandleRequestWithParamsAndError(func(params map[string]string) (apiadmin.ClusterInfo, error) {
+		return app.clusters.Get(params["clusterId"])
+	})).Methods("GET")
+	app.router.HandleFunc("/admin/clusters/{clusterId}", infrastructure.HandleRequestWithParamsAndError(func(params map[string]string) (apiadmin.ClusterInfo, error) {
+		return app.clusters.Delete(params["clusterId"])
+	})).Methods("DELETE")
 }
 
 func (app *AdapterApplication) registerRoutes() {
 	// Simulator API
-	app.router.HandleFunc("/updateNodes", infrastructure.HandleRequestWithJSONBody(app.sim2.NodeUpdates().Post)).Methods("POST")
-	app.router.HandleFunc("/updatePods", infrastructure.HandleRequestWithJSONBody(app.sim2.PodUpdates().Post)).Methods("POST")
-	app.router.HandleFunc("/getEventsApiEvents", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		eventList := app.sim2.Events().GetEventsApiEvents()
-		eventsv1GV := eventsv1.SchemeGroupVersion
-		eventsV1Codec := scheme.Codecs.CodecForVersions(scheme.Codecs.LegacyCodec(eventsv1GV), scheme.Codecs.UniversalDecoder(eventsv1GV), eventsv1GV, eventsv1GV)
-		encodedEventList, err := runtime.Encode(eventsV1Codec, &eventList)
-		if err != nil {
-			klog.V(1).ErrorS(err, "There was an error encoding the events. err = ", err)
-			w.WriteHeader(500)
-			return
-		}
-		w.Write(encodedEventList)
-	}).Methods("GET")
+	app.registerSimulatorRoutes()
+
+	// Administration
+	app.router.HandleFunc("/admin/reset", infrastructure.HandleJSONRequest(app.admin.Reset().Post)).Methods("POST")
+	app.router.HandleFunc("/admin/bootstrap", infrastructure.HandleRequestWithBodyAndError(app.admin.Bootstrap().Post)).Methods("POST")
+	app.router.HandleFunc("/admin/export", app.exportManifests).Methods("GET")
+	app.router.HandleFunc("/admin/apiusage", infrastructure.HandleJSONRequest(app.admin.ApiUsage().Get)).Methods("GET")
+	app.router.HandleFunc("/admin/apiusage", infrastructure.HandleJSONRequest(app.admin.ApiUsage().Delete)).Methods("DELETE")
+	app.router.HandleFunc("/admin/faults", infrastructure.HandleJSONRequest(app.admin.Faults().Get)).Methods("GET")
+	app.router.HandleFunc("/admin/faults", infrastructure.HandleRequestWithJSONBodyAndError(app.admin.Faults().Put)).Methods("PUT")
+	app.router.HandleFunc("/admin/faults", infrastructure.HandleJSONRequest(app.admin.Faults().Delete)).Methods("DELETE")
+
+	// Dashboard
+	app.router.HandleFunc("/dashboard/", app.board.Page()).Methods("GET")
+	app.router.HandleFunc("/dashboard/state", infrastructure.HandleJSONRequest(app.board.State().GetState)).Methods("GET")
+	app.router.HandleFunc("/dashboard/stream", app.board.Stream()).Methods("GET")
 
-	app.router.HandleFunc("/getCoreApiEvents", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		eventList := app.sim2.Events().GetCoreApiEvents()
-		corev1GV := v1.SchemeGroupVersion
-		coreV1Codec := scheme.Codecs.CodecForVersions(scheme.Codecs.LegacyCodec(corev1GV), scheme.Codecs.UniversalDecoder(corev1GV), corev1GV, corev1GV)
-		encodedEventList, err := runtime.Encode(coreV1Codec, &eventList)
-		if err != nil {
-			klog.V(1).ErrorS(err, "There was an error encoding the events. err = ", err)
-			w.WriteHeader(500)
-			return
-		}
-		w.Write(encodedEventList)
-	}).Methods("GET")
 	// Kubeserver API
 	app.router.HandleFunc("/api", infrastructure.HandleJSONRequest(app.kube2.Api().Get)).Methods("GET")
 	app.router.HandleFunc("/api/v1", infrastructure.HandleJSONRequest(app.kube2.Api().V1().Get)).Methods("GET")
@@ -116,11 +326,17 @@ func (app *AdapterApplication) registerRoutes() {
 
 	app.router.HandleFunc("/api/v1/namespaces/kube-system/configmaps/cluster-autoscaler-status", infrastructure.HandleJSONRequest(app.kube2.Api().V1().Namespaces().Namespace("kube-system").Configmaps().ClusterAutoscalerStatus().Get)).Methods("GET")
 	app.router.HandleFunc("/api/v1/namespaces/kube-system/configmaps/cluster-autoscaler-status", infrastructure.HandleRequestWithJSONBody(app.kube2.Api().V1().Namespaces().Namespace("kube-system").Configmaps().ClusterAutoscalerStatus().Put)).Methods("PUT")
+	app.router.HandleFunc("/api/v1/namespaces/{namespace}/configmaps/{configmapName}", infrastructure.HandleRequestWithParamsAndError(func(params map[string]string) (v1.ConfigMap, error) {
+		return app.kube2.Api().V1().Namespaces().Namespace(params["namespace"]).Configmaps().Configmap(params["configmapName"]).Get()
+	})).Methods("GET")
+	app.router.HandleFunc("/api/v1/configmaps", infrastructure.HandleWatchableRequest(app.kube2.Api().V1().ConfigMaps().Get)).Methods("GET")
 	app.router.HandleFunc("/api/v1/services", infrastructure.UnsupportedResource()).Methods("GET")
 	app.router.HandleFunc("/api/v1/persistentvolumes", infrastructure.UnsupportedResource()).Methods("GET")
 	app.router.HandleFunc("/api/v1/persistentvolumeclaims", infrastructure.UnsupportedResource()).Methods("GET")
 	app.router.HandleFunc("/api/v1/replicationcontrollers", infrastructure.UnsupportedResource()).Methods("GET")
-	app.router.HandleFunc("/apis", infrastructure.HandleJSONRequest(app.kube2.Apis().Get)).Methods("GET")
+	app.router.HandleFunc("/apis", infrastructure.HandleJSONRequest(func() metav1.APIGroupList {
+		return servedAPIGroups(app.kube2.Apis().Get(), app.config)
+	})).Methods("GET")
 	app.router.HandleFunc("/apis/apps", infrastructure.UnsupportedResource()).Methods("GET")
 	app.router.HandleFunc("/apis/apps/v1/daemonsets", infrastructure.HandleWatchableRequest(app.kube2.Apis().Apps().V1().DaemonSets().Get)).Methods("GET")
 	app.router.HandleFunc("/apis/apps/v1/replicasets", infrastructure.UnsupportedResource()).Methods("GET")
@@ -128,6 +344,28 @@ func (app *AdapterApplication) registerRoutes() {
 	app.router.HandleFunc("/apis/autoscaling/v1", infrastructure.HandleJSONRequest(app.kube2.Apis().Autoscaling().V1().Get)).Methods("GET")
 	app.router.HandleFunc("/apis/batch/v1/jobs", infrastructure.UnsupportedResource()).Methods("GET")
 
+	app.router.HandleFunc("/api/v1/namespaces/{namespace}/pods/{podName}/eviction", func(w http.ResponseWriter, r *http.Request) {
+		klog.V(7).Infof("Req: %s%s?%s", r.Host, r.URL.Path, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		reqBody, _ := io.ReadAll(r.Body)
+		u := &policy.Eviction{}
+		// Components send evictions either as JSON or as protobuf
+		err := runtime.DecodeInto(scheme.Codecs.UniversalDeserializer(), reqBody, u)
+		if err != nil {
+			klog.V(1).ErrorS(err, "There was an error decoding the eviction. err = ", err)
+			w.WriteHeader(500)
+			return
+		}
+		pathParams := mux.Vars(r)
+		eviction, err := app.kube2.Api().V1().Namespaces().Namespace(pathParams["namespace"]).Pods().Pod(pathParams["podName"]).Eviction().Post(*u)
+		if err != nil {
+			infrastructure.WriteError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(eviction)
+	}).Methods("POST")
+
 	app.router.HandleFunc("/api/v1/namespaces/{namespace}/events", infrastructure.HandleRequestWithParamsAndJSONBody(
 		func(params map[string]string, body v1.Event) v1.Event {
 			return app.kube2.Api().V1().Namespaces().Namespace(params["namespace"]).Events().Post(body)
@@ -154,6 +392,8 @@ func (app *AdapterApplication) registerRoutes() {
 	}).Methods("POST", "PUT", "PATCH")
 
 	app.router.HandleFunc("/apis/policy/v1/poddisruptionbudgets", infrastructure.UnsupportedResource()).Methods("GET")
+	app.router.HandleFunc("/apis/scheduling.k8s.io/v1", infrastructure.HandleJSONRequest(app.kube2.Apis().Scheduling().V1().Get)).Methods("GET")
+	app.router.HandleFunc("/apis/scheduling.k8s.io/v1/priorityclasses", infrastructure.HandleWatchableRequest(app.kube2.Apis().Scheduling().V1().PriorityClasses().Get)).Methods("GET")
 	app.router.HandleFunc("/apis/storage.k8s.io/v1/storageclasses", infrastructure.UnsupportedResource()).Methods("GET")
 	app.router.HandleFunc("/apis/storage.k8s.io/v1/csidrivers", infrastructure.UnsupportedResource()).Methods("GET")
 	app.router.HandleFunc("/apis/storage.k8s.io/v1/csinodes", infrastructure.UnsupportedResource()).Methods("GET")
@@ -1,7 +1,9 @@
 package simulation
 
 import (
+	"go-kube/internal/infrastructure"
 	"go-kube/pkg/control"
+	"go-kube/pkg/results"
 	"go-kube/pkg/storage"
 )
 
@@ -9,24 +11,39 @@ type SimulationApi interface {
 	NodeUpdates() control.NodeUpdatesResource
 	PodUpdates() control.PodUpdatesResource
 	Events() control.EventsResource
+	Timeline() control.TimelineResource
+	Components() control.ComponentsResource
+	Stream() infrastructure.Endpoint
 }
 
 type SimulationApiImpl struct {
 	storage *storage.StorageContainer
+	// If set, warnings of the payload validation reject the request as well
+	strictValidation bool
+	// Records the outcome of every round, nil if disabled
+	results *results.Recorder
 }
 
 func (impl SimulationApiImpl) NodeUpdates() control.NodeUpdatesResource {
-	return control.NewNodeUpdateResource(impl.storage)
+	return control.NewNodeUpdateResource(impl.storage, impl.strictValidation)
 }
 
 func (impl SimulationApiImpl) PodUpdates() control.PodUpdatesResource {
-	return control.NewPodUpdateResource(impl.storage)
+	return control.NewPodUpdateResource(impl.storage, impl.strictValidation, impl.results)
 }
 
 func (impl SimulationApiImpl) Events() control.EventsResource {
 	return control.NewEventsResource(impl.storage)
 }
 
-func NewSimulationApi(storage *storage.StorageContainer) SimulationApiImpl {
-	return SimulationApiImpl{storage: storage}
+func (impl SimulationApiImpl) Timeline() control.TimelineResource {
+	return control.NewTimelineResource(impl.storage)
+}
+
+func (impl SimulationApiImpl) Components() control.ComponentsResource {
+	return control.NewComponentsResource(impl.storage)
+}
+
+func NewSimulationApi(storage *storage.StorageContainer, strictValidation bool, results *results.Recorder) SimulationApiImpl {
+	return SimulationApiImpl{storage: storage, strictValidation: strictValidation, results: results}
 }
@@ -0,0 +1,210 @@
+package simulation
+
+import (
+	"context"
+	"go-kube/internal/infrastructure"
+	"go-kube/pkg/misim"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Time allowed to write a message to the simulation
+	streamWriteWait = 10 * time.Second
+	// Time allowed between two pongs of the simulation before the connection is considered dropped
+	streamPongWait = 30 * time.Second
+	// Interval of pings sent to the simulation, must be less than streamPongWait
+	streamPingPeriod = streamPongWait / 3
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1 << 16,
+	WriteBufferSize: 1 << 16,
+	// The simulation is no browser, so we do not check the origin
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Bidirectional simulator channel. The simulation sends NodeUpdate and PodsUpdate
+// messages, the adapter answers each of them and additionally streams
+// bindings, failures, node changes and evictions as soon as they happen.
+func (impl SimulationApiImpl) Stream() infrastructure.Endpoint {
+	return func(w http.ResponseWriter, r *http.Request) {
+		klog.V(7).Infof("Req: %s%s?%s", r.Host, r.URL.Path, r.URL.RawQuery)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			klog.V(1).ErrorS(err, "Unable to upgrade simulator stream")
+			return
+		}
+		defer conn.Close()
+		klog.V(1).Infof("Simulation connected to stream (%s)", r.RemoteAddr)
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		outgoing := make(chan misim.StreamMessage, 500)
+		requests := make(chan misim.StreamMessage, 16)
+
+		go impl.writeStream(ctx, cancel, conn, outgoing)
+		go impl.processStream(ctx, requests, outgoing)
+		impl.forwardProgress(ctx, cancel, outgoing)
+
+		impl.readStream(ctx, conn, requests)
+		klog.V(1).Infof("Simulation disconnected from stream (%s)", r.RemoteAddr)
+	}
+}
+
+// Subscribes to the progress updates and sends them to the simulation until ctx is done. The
+// subscription is dropped if the simulation does not keep up, so a slow connection never stalls
+// the bindings of the round. The stream is closed in that case, the simulation would miss updates otherwise.
+func (impl SimulationApiImpl) forwardProgress(ctx context.Context, cancel context.CancelFunc, outgoing chan<- misim.StreamMessage) {
+	progressBroadcaster := impl.storage.Progress.GetProgressBroadcaster()
+	progressChannel := progressBroadcaster.SubscribeDropIfSlow()
+	go func() {
+		defer progressBroadcaster.CancelSubscription(progressChannel)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update, ok := <-progressChannel:
+				if !ok {
+					klog.V(1).Info("Closing simulator stream, it fell behind or the cluster stopped")
+					cancel()
+					return
+				}
+				select {
+				case outgoing <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+}
+
+// Reads messages of the simulation until the connection is closed or dropped
+func (impl SimulationApiImpl) readStream(ctx context.Context, conn *websocket.Conn, requests chan<- misim.StreamMessage) {
+	defer close(requests)
+	conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+	})
+	for {
+		var message misim.StreamMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				klog.V(1).ErrorS(err, "Simulator stream dropped")
+			}
+			return
+		}
+		// Any message proves the connection is alive
+		conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		select {
+		case requests <- message:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Processes the requests of the simulation one after another
+func (impl SimulationApiImpl) processStream(ctx context.Context, requests <-chan misim.StreamMessage, outgoing chan<- misim.StreamMessage) {
+	for message := range requests {
+		var response misim.StreamMessage
+		switch message.Type {
+		case misim.StreamNodeUpdate, misim.StreamNodeSync:
+			if message.NodeUpdate == nil {
+				response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: string(message.Type) + " message without payload"}
+				break
+			}
+			var result misim.NodeUpdateResponse
+			var err error
+			if message.Type == misim.StreamNodeSync {
+				result, err = impl.NodeUpdates().PostSync(*message.NodeUpdate)
+			} else {
+				result, err = impl.NodeUpdates().Post(*message.NodeUpdate)
+			}
+			if err != nil {
+				response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: err.Error()}
+				break
+			}
+			response = misim.StreamMessage{Type: misim.StreamNodeUpdateResponse, Id: message.Id, NodeUpdateResponse: &result}
+		case misim.StreamPodsUpdate, misim.StreamPodsSync:
+			if message.PodsUpdate == nil {
+				response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: string(message.Type) + " message without payload"}
+				break
+			}
+			var result misim.PodsUpdateResponse
+			var err error
+			if message.Type == misim.StreamPodsSync {
+				result, err = impl.PodUpdates().PostSync(*message.PodsUpdate)
+			} else {
+				result, err = impl.PodUpdates().Post(*message.PodsUpdate)
+			}
+			if err != nil {
+				response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: err.Error()}
+				break
+			}
+			response = misim.StreamMessage{Type: misim.StreamPodsUpdateResponse, Id: message.Id, PodsUpdateResponse: &result}
+		case misim.StreamNodeDelta:
+			if message.NodeDelta == nil {
+				response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: "NodeDelta message without payload"}
+				break
+			}
+			result, err := impl.NodeUpdates().PostDelta(*message.NodeDelta)
+			if err != nil {
+				response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: err.Error()}
+				break
+			}
+			response = misim.StreamMessage{Type: misim.StreamNodeUpdateResponse, Id: message.Id, NodeUpdateResponse: &result}
+		case misim.StreamPodsDelta:
+			if message.PodsDelta == nil {
+				response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: "PodsDelta message without payload"}
+				break
+			}
+			result, err := impl.PodUpdates().PostDelta(*message.PodsDelta)
+			if err != nil {
+				response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: err.Error()}
+				break
+			}
+			response = misim.StreamMessage{Type: misim.StreamPodsUpdateResponse, Id: message.Id, PodsUpdateResponse: &result}
+		default:
+			response = misim.StreamMessage{Type: misim.StreamError, Id: message.Id, Error: "unknown message type " + string(message.Type)}
+		}
+		select {
+		case outgoing <- response:
+		case <-ctx.Done():
+			klog.V(1).Infof("Simulator stream closed before response to %s %s could be sent", message.Type, message.Id)
+		}
+	}
+}
+
+// Single writer of the connection, also keeps the connection alive with pings
+func (impl SimulationApiImpl) writeStream(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, outgoing <-chan misim.StreamMessage) {
+	// Closing the connection also unblocks the reader if writing failed
+	defer conn.Close()
+	defer cancel()
+	ticker := time.NewTicker(streamPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(streamWriteWait))
+			return
+		case message := <-outgoing:
+			klog.V(6).Infof("Sending %s on simulator stream", message.Type)
+			conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := conn.WriteJSON(message); err != nil {
+				klog.V(1).ErrorS(err, "Unable to write to simulator stream")
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteWait)); err != nil {
+				klog.V(1).ErrorS(err, "Simulator stream dropped")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"go-kube/pkg/control"
+	"go-kube/pkg/misim"
+	"go-kube/pkg/storage/inmemorystorage"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A simulation that stops reading its stream must not stall the bindings of the round
+func TestStreamNotReadDoesNotBlockBindings(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	storage := inmemorystorage.NewStorageContainer(ctx, nil, 1000)
+	impl := NewSimulationApi(&storage, false, nil)
+
+	// Nobody reads the outgoing messages, like a connection whose writer is stuck
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	defer streamCancel()
+	impl.forwardProgress(streamCtx, streamCancel, make(chan misim.StreamMessage))
+
+	// More bindings than the queues of the progress storage, the broadcaster and the subscriber hold
+	pods := v1.PodList{Items: make([]v1.Pod, 4000)}
+	for i := range pods.Items {
+		pods.Items[i] = v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "default"}}
+	}
+	controller := control.NewPodController(&storage)
+	responses := make(chan misim.PodsUpdateResponse, 1)
+	go func() {
+		responses <- controller.UpdatePods(pods, nil, pods, false)
+	}()
+	for storage.Pods.PodsToBePlaced().Size() != len(pods.Items) {
+		time.Sleep(time.Millisecond)
+	}
+
+	bound := make(chan struct{})
+	go func() {
+		for _, pod := range pods.Items {
+			controller.BindPod(pod.Name, "node-0")
+		}
+		close(bound)
+	}()
+	select {
+	case response := <-responses:
+		if len(response.Binded) != len(pods.Items) {
+			t.Fatalf("round answered with %d bindings, want %d", len(response.Binded), len(pods.Items))
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("round did not complete while the stream was not read")
+	}
+	<-bound
+	if stats := storage.Progress.GetProgressBroadcaster().Stats(); stats.DroppedSubscribers != 1 {
+		t.Fatalf("broadcaster dropped %d subscribers, want the stream to be dropped", stats.DroppedSubscribers)
+	}
+}
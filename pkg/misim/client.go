@@ -0,0 +1,306 @@
+package misim
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cluster "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Typed client for the simulator API of the adapter
+type Client struct {
+	baseUrl      string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+type ClientOption func(*Client)
+
+// Uses the passed http client instead of http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Retries requests that could not be delivered or were answered with 502, 503 or 504
+// up to maxRetries times. The backoff doubles with every retry.
+func WithRetries(maxRetries int, backoff time.Duration) ClientOption {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// Creates a client for the adapter at baseUrl, e.g. "http://localhost:8000"
+func NewClient(baseUrl string, options ...ClientOption) *Client {
+	client := &Client{
+		baseUrl:      strings.TrimSuffix(baseUrl, "/"),
+		httpClient:   http.DefaultClient,
+		maxRetries:   3,
+		retryBackoff: 100 * time.Millisecond,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// Posts the nodes together with the events of the simulation
+func (c *Client) UpdateNodes(ctx context.Context, request NodeUpdateRequest) (NodeUpdateResponse, error) {
+	var response NodeUpdateResponse
+	err := c.do(ctx, http.MethodPost, ApiPrefix+"/updateNodes", request, &response)
+	return response, err
+}
+
+// Posts the nodes, the adapter derives the watch events itself
+func (c *Client) SyncNodes(ctx context.Context, request NodeUpdateRequest) (NodeUpdateResponse, error) {
+	var response NodeUpdateResponse
+	err := c.do(ctx, http.MethodPost, ApiPrefix+"/updateNodes?mode=sync", request, &response)
+	return response, err
+}
+
+// Posts only the changed nodes
+func (c *Client) UpdateNodesDelta(ctx context.Context, request NodeDeltaRequest) (NodeUpdateResponse, error) {
+	var response NodeUpdateResponse
+	err := c.do(ctx, http.MethodPost, ApiPrefix+"/updateNodesDelta", request, &response)
+	return response, err
+}
+
+// Posts the pods together with the events of the simulation and
+// blocks until all pods to be placed are bound or failed
+func (c *Client) UpdatePods(ctx context.Context, request PodsUpdateRequest) (PodsUpdateResponse, error) {
+	var response PodsUpdateResponse
+	err := c.do(ctx, http.MethodPost, ApiPrefix+"/updatePods", request, &response)
+	return response, err
+}
+
+// Like UpdatePods, but the adapter derives the watch events itself
+func (c *Client) SyncPods(ctx context.Context, request PodsUpdateRequest) (PodsUpdateResponse, error) {
+	var response PodsUpdateResponse
+	err := c.do(ctx, http.MethodPost, ApiPrefix+"/updatePods?mode=sync", request, &response)
+	return response, err
+}
+
+// Like UpdatePods, but posts only the changed pods
+func (c *Client) UpdatePodsDelta(ctx context.Context, request PodsDeltaRequest) (PodsUpdateResponse, error) {
+	var response PodsUpdateResponse
+	err := c.do(ctx, http.MethodPost, ApiPrefix+"/updatePodsDelta", request, &response)
+	return response, err
+}
+
+// Returns all events received through the events.k8s.io API
+func (c *Client) GetEventsApiEvents(ctx context.Context) (eventsv1.EventList, error) {
+	var response eventsv1.EventList
+	err := c.do(ctx, http.MethodGet, ApiPrefix+"/eventsApiEvents", nil, &response)
+	return response, err
+}
+
+// Returns all events received through the core API
+func (c *Client) GetCoreApiEvents(ctx context.Context) (v1.EventList, error) {
+	var response v1.EventList
+	err := c.do(ctx, http.MethodGet, ApiPrefix+"/coreApiEvents", nil, &response)
+	return response, err
+}
+
+// Returns the nodes currently stored in the adapter
+func (c *Client) GetNodes(ctx context.Context) (v1.NodeList, error) {
+	var response v1.NodeList
+	err := c.do(ctx, http.MethodGet, "/api/v1/nodes", nil, &response)
+	return response, err
+}
+
+// Returns the pods currently stored in the adapter
+func (c *Client) GetPods(ctx context.Context) (v1.PodList, error) {
+	var response v1.PodList
+	err := c.do(ctx, http.MethodGet, "/api/v1/pods", nil, &response)
+	return response, err
+}
+
+// Returns the machine sets currently stored in the adapter
+func (c *Client) GetMachineSets(ctx context.Context) (cluster.MachineSetList, error) {
+	var response cluster.MachineSetList
+	err := c.do(ctx, http.MethodGet, "/apis/cluster.x-k8s.io/v1beta1/machinesets", nil, &response)
+	return response, err
+}
+
+// Returns the machines currently stored in the adapter
+func (c *Client) GetMachines(ctx context.Context) (cluster.MachineList, error) {
+	var response cluster.MachineList
+	err := c.do(ctx, http.MethodGet, "/apis/cluster.x-k8s.io/v1beta1/machines", nil, &response)
+	return response, err
+}
+
+// Returns the components that used the Kubernetes API of the adapter
+func (c *Client) Components(ctx context.Context) ([]Component, error) {
+	var response []Component
+	err := c.do(ctx, http.MethodGet, ApiPrefix+"/components", nil, &response)
+	return response, err
+}
+
+// Checks whether the required components are connected, the default components if required is empty.
+// If wait is positive, the adapter answers as soon as they are or after wait at the latest.
+// Components that are not ready are no error, the readiness tells what is missing.
+func (c *Client) ComponentsReady(ctx context.Context, required []string, wait time.Duration) (ComponentsReadiness, error) {
+	query := url.Values{}
+	if len(required) > 0 {
+		query.Set("require", strings.Join(required, ","))
+	}
+	if wait > 0 {
+		query.Set("wait", wait.String())
+	}
+	path := ApiPrefix + "/components/ready"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseUrl+path, nil)
+	if err != nil {
+		return ComponentsReadiness{}, err
+	}
+	request.Header.Set("Accept", "application/json")
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return ComponentsReadiness{}, err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return ComponentsReadiness{}, err
+	}
+	// 503 is the answer for components that are not ready, it must not be retried like in do
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusServiceUnavailable {
+		return ComponentsReadiness{}, statusError(http.MethodGet, path, response.StatusCode, responseBody)
+	}
+	var status metav1.Status
+	if err := json.Unmarshal(responseBody, &status); err == nil && status.Kind == "Status" {
+		// e.g. the adapter is shutting down
+		return ComponentsReadiness{}, apierrors.FromObject(&status)
+	}
+	var readiness ComponentsReadiness
+	if err := json.Unmarshal(responseBody, &readiness); err != nil {
+		return ComponentsReadiness{}, fmt.Errorf("unable to decode response of GET %s: %w", path, err)
+	}
+	return readiness, nil
+}
+
+// Opens the bidirectional simulator stream
+func (c *Client) OpenStream(ctx context.Context) (*Stream, error) {
+	url := "ws" + strings.TrimPrefix(c.baseUrl, "http") + ApiPrefix + "/stream"
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{conn: conn}, nil
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, body any, into any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.doOnce(ctx, method, path, payload, into)
+		if err == nil || !retry || attempt >= c.maxRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Sends a single request, returns whether it may be retried if it failed
+func (c *Client) doOnce(ctx context.Context, method string, path string, payload []byte, into any) (bool, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, body)
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		// Only requests that never reached the adapter are safe to be repeated,
+		// as a pod update might already be processed
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial" && ctx.Err() == nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return false, err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		retry := response.StatusCode == http.StatusBadGateway || response.StatusCode == http.StatusServiceUnavailable || response.StatusCode == http.StatusGatewayTimeout
+		return retry, statusError(method, path, response.StatusCode, responseBody)
+	}
+	if err := json.Unmarshal(responseBody, into); err != nil {
+		return false, fmt.Errorf("unable to decode response of %s %s: %w", method, path, err)
+	}
+	return false, nil
+}
+
+// Converts an error response into an error that can be inspected with the apierrors package
+func statusError(method string, path string, code int, body []byte) error {
+	var status metav1.Status
+	if err := json.Unmarshal(body, &status); err == nil && status.Kind == "Status" {
+		return apierrors.FromObject(&status)
+	}
+	return apierrors.NewGenericServerResponse(code, method, schema.GroupResource{Resource: path}, "", string(body), 0, true)
+}
+
+// Bidirectional simulator stream, see StreamMessage
+type Stream struct {
+	conn *websocket.Conn
+}
+
+// Sends a message to the adapter
+func (s *Stream) Send(message StreamMessage) error {
+	return s.conn.WriteJSON(message)
+}
+
+// Blocks until the next message of the adapter arrives. Pings of the adapter
+// are only answered while receiving, so the stream has to be read continuously.
+func (s *Stream) Receive() (StreamMessage, error) {
+	var message StreamMessage
+	err := s.conn.ReadJSON(&message)
+	return message, err
+}
+
+// Closes the stream gracefully
+func (s *Stream) Close() error {
+	s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	return s.conn.Close()
+}
@@ -0,0 +1,190 @@
+package misim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+// The status as written by the adapter, see infrastructure.WriteError
+func statusOf(err apierrors.APIStatus) metav1.Status {
+	status := err.Status()
+	status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	return status
+}
+
+// Returns an http client whose first failures connections are refused, like by an adapter that is still starting
+func refusingClient(failures int32) (*http.Client, *atomic.Int32) {
+	dials := &atomic.Int32{}
+	dialer := &net.Dialer{}
+	transport := &http.Transport{DialContext: func(ctx context.Context, network string, address string) (net.Conn, error) {
+		if dials.Add(1) <= failures {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+		}
+		return dialer.DialContext(ctx, network, address)
+	}}
+	return &http.Client{Transport: transport}, dials
+}
+
+func TestClientRetriesDialErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, PodsUpdateResponse{})
+	}))
+	defer server.Close()
+
+	httpClient, dials := refusingClient(2)
+	client := NewClient(server.URL, WithHTTPClient(httpClient), WithRetries(3, time.Millisecond))
+	if _, err := client.UpdatePods(context.Background(), PodsUpdateRequest{}); err != nil {
+		t.Fatalf("UpdatePods returned error %v", err)
+	}
+	if dials.Load() != 3 {
+		t.Fatalf("client dialed %d times, want 3", dials.Load())
+	}
+
+	// Without retries left, the dial error is returned
+	httpClient, dials = refusingClient(2)
+	client = NewClient(server.URL, WithHTTPClient(httpClient), WithRetries(1, time.Millisecond))
+	if _, err := client.UpdatePods(context.Background(), PodsUpdateRequest{}); err == nil {
+		t.Fatal("UpdatePods succeeded although every dial failed")
+	}
+	if dials.Load() != 2 {
+		t.Fatalf("client dialed %d times, want 2", dials.Load())
+	}
+}
+
+// A pods update that reached the adapter may have been processed, so it is never repeated
+func TestClientDoesNotRetryAfterSending(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// The connection breaks before the adapter answers
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("unable to hijack the connection: %v", err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, WithRetries(3, time.Millisecond))
+	if _, err := client.UpdatePods(context.Background(), PodsUpdateRequest{}); err == nil {
+		t.Fatal("UpdatePods succeeded although the connection broke")
+	}
+	if requests.Load() != 1 {
+		t.Fatalf("adapter received %d requests, want 1", requests.Load())
+	}
+}
+
+func TestClientStatusErrors(t *testing.T) {
+	invalid := apierrors.NewInvalid(schema.GroupKind{Group: "misim", Kind: "PodsUpdateRequest"}, "", field.ErrorList{field.NotFound(field.NewPath("podsToBePlaced"), "p")})
+	tests := []struct {
+		name  string
+		code  int
+		body  any
+		check func(error) bool
+		// Number of requests the adapter receives with 2 retries
+		wantRequests int32
+	}{
+		{name: "invalid", code: http.StatusUnprocessableEntity, body: statusOf(invalid), check: apierrors.IsInvalid, wantRequests: 1},
+		{name: "conflict", code: http.StatusConflict, body: statusOf(apierrors.NewConflict(v1.Resource("pods"), "p", errors.New("changed"))), check: apierrors.IsConflict, wantRequests: 1},
+		{name: "internal error without status", code: http.StatusInternalServerError, body: "broken", check: apierrors.IsInternalError, wantRequests: 1},
+		// The adapter rejects requests while it shuts down, nothing was processed
+		{name: "unavailable", code: http.StatusServiceUnavailable, body: statusOf(apierrors.NewServiceUnavailable("shutting down")), check: apierrors.IsServiceUnavailable, wantRequests: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if message, ok := test.body.(string); ok {
+					w.WriteHeader(test.code)
+					w.Write([]byte(message))
+					return
+				}
+				writeJSON(w, test.code, test.body)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, WithRetries(2, time.Millisecond))
+			_, err := client.UpdatePods(context.Background(), PodsUpdateRequest{})
+			if !test.check(err) {
+				t.Fatalf("UpdatePods returned error %v (%s)", err, apierrors.ReasonForError(err))
+			}
+			if requests.Load() != test.wantRequests {
+				t.Fatalf("adapter received %d requests, want %d", requests.Load(), test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClientDecodesResponses(t *testing.T) {
+	node := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case ApiPrefix + "/updateNodes?mode=sync":
+			var request NodeUpdateRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.AllNodes.Items) != 1 {
+				t.Errorf("adapter received nodes %v, %v", request.AllNodes.Items, err)
+			}
+			writeJSON(w, http.StatusOK, NodeUpdateResponse{Data: request.AllNodes})
+		case ApiPrefix + "/updatePods?":
+			writeJSON(w, http.StatusOK, PodsUpdateResponse{
+				Binded:   []BindingInformation{{Pod: "pod-1", Node: "node-1"}},
+				Failed:   []BindingFailureInformation{{Pod: "pod-2", Message: "no fit"}},
+				NewNodes: []v1.Node{node},
+			})
+		case "/api/v1/pods?":
+			writeJSON(w, http.StatusOK, v1.PodList{Items: []v1.Pod{pod}})
+		case "/api/v1/nodes?":
+			writeJSON(w, http.StatusOK, v1.NodeList{Items: []v1.Node{node}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL)
+	ctx := context.Background()
+
+	nodeResponse, err := client.SyncNodes(ctx, NodeUpdateRequest{AllNodes: v1.NodeList{Items: []v1.Node{node}}})
+	if err != nil || len(nodeResponse.Data.Items) != 1 || nodeResponse.Data.Items[0].Name != "node-1" {
+		t.Fatalf("SyncNodes returned %v, %v", nodeResponse, err)
+	}
+	podsResponse, err := client.UpdatePods(ctx, PodsUpdateRequest{})
+	if err != nil {
+		t.Fatalf("UpdatePods returned error %v", err)
+	}
+	if len(podsResponse.Binded) != 1 || podsResponse.Binded[0].Node != "node-1" ||
+		len(podsResponse.Failed) != 1 || podsResponse.Failed[0].Message != "no fit" ||
+		len(podsResponse.NewNodes) != 1 || podsResponse.NewNodes[0].Name != "node-1" {
+		t.Fatalf("UpdatePods returned %+v", podsResponse)
+	}
+	pods, err := client.GetPods(ctx)
+	if err != nil || len(pods.Items) != 1 || pods.Items[0].Name != "pod-1" {
+		t.Fatalf("GetPods returned %v, %v", pods.Items, err)
+	}
+	nodes, err := client.GetNodes(ctx)
+	if err != nil || len(nodes.Items) != 1 || nodes.Items[0].Name != "node-1" {
+		t.Fatalf("GetNodes returned %v, %v", nodes.Items, err)
+	}
+}
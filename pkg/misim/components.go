@@ -0,0 +1,29 @@
+package misim
+
+import "time"
+
+// Kubernetes component using the API of the adapter, identified by its User-Agent
+type Component struct {
+	// Program name at the start of the User-Agent, e.g. kube-scheduler
+	Name      string `json:"name"`
+	UserAgent string `json:"userAgent"`
+	// Number of open watches per resource, e.g. pods
+	Watches  map[string]int `json:"watches"`
+	LastSeen time.Time      `json:"lastSeen"`
+}
+
+// Resources the known components watch once they have started
+var ComponentWatches = map[string][]string{
+	"kube-scheduler":     {"pods", "nodes"},
+	"cluster-autoscaler": {"machinesets"},
+}
+
+// Whether the components required for a simulation are connected
+type ComponentsReadiness struct {
+	Ready bool `json:"ready"`
+	// Components the readiness was checked for
+	Required []string `json:"required"`
+	// What is missing for readiness, e.g. "kube-scheduler does not watch nodes"
+	Missing    []string    `json:"missing"`
+	Components []Component `json:"components"`
+}
@@ -0,0 +1,54 @@
+package misim
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// The unversioned simulator routes (e.g. /updatePods) keep the JSON shapes
+// from before the versioned API, so the MiSim extension can migrate gradually.
+// Requests need no legacy types, as JSON keys are matched case-insensitively.
+
+// Legacy JSON shape of BindingInformation
+type LegacyBindingInformation struct {
+	Pod  string
+	Node string
+}
+
+// Legacy JSON shape of BindingFailureInformation
+type LegacyBindingFailureInformation struct {
+	Pod     string
+	Message string
+}
+
+// Legacy JSON shape of NodeUpdateResponse
+type LegacyNodeUpdateResponse struct {
+	Data v1.NodeList `json:"Updated NodeList with"`
+}
+
+// Legacy JSON shape of PodsUpdateResponse
+type LegacyPodsUpdateResponse struct {
+	Failed       []LegacyBindingFailureInformation
+	Binded       []LegacyBindingInformation
+	NewNodes     []v1.Node
+	DeletedNodes []v1.Node
+}
+
+func ToLegacyNodeUpdateResponse(response NodeUpdateResponse) LegacyNodeUpdateResponse {
+	return LegacyNodeUpdateResponse(response)
+}
+
+func ToLegacyPodsUpdateResponse(response PodsUpdateResponse) LegacyPodsUpdateResponse {
+	legacy := LegacyPodsUpdateResponse{
+		Failed:       make([]LegacyBindingFailureInformation, len(response.Failed)),
+		Binded:       make([]LegacyBindingInformation, len(response.Binded)),
+		NewNodes:     response.NewNodes,
+		DeletedNodes: response.DeletedNodes,
+	}
+	for i, failure := range response.Failed {
+		legacy.Failed[i] = LegacyBindingFailureInformation(failure)
+	}
+	for i, binding := range response.Binded {
+		legacy.Binded[i] = LegacyBindingInformation(binding)
+	}
+	return legacy
+}
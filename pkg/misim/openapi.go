@@ -0,0 +1,10 @@
+package misim
+
+import (
+	_ "embed"
+)
+
+// OpenAPI document of the versioned simulator API, served at ApiPrefix + "/openapi.json"
+//
+//go:embed openapi.json
+var OpenApiDocument []byte
@@ -0,0 +1,66 @@
+package misim
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// Type of a message exchanged on the simulator stream
+type StreamMessageType string
+
+const (
+	// Sent by the simulation, carries a NodeUpdateRequest
+	StreamNodeUpdate StreamMessageType = "NodeUpdate"
+	// Sent by the simulation, carries a PodsUpdateRequest
+	StreamPodsUpdate StreamMessageType = "PodsUpdate"
+	// Sent by the simulation, carries a NodeUpdateRequest whose events are derived by the adapter
+	StreamNodeSync StreamMessageType = "NodeSync"
+	// Sent by the simulation, carries a PodsUpdateRequest whose events are derived by the adapter
+	StreamPodsSync StreamMessageType = "PodsSync"
+	// Sent by the simulation, carries a NodeDeltaRequest
+	StreamNodeDelta StreamMessageType = "NodeDelta"
+	// Sent by the simulation, carries a PodsDeltaRequest
+	StreamPodsDelta StreamMessageType = "PodsDelta"
+
+	// Sent by the adapter after a NodeUpdate has been processed
+	StreamNodeUpdateResponse StreamMessageType = "NodeUpdateResponse"
+	// Sent by the adapter after all pods of a PodsUpdate have been placed (or failed)
+	StreamPodsUpdateResponse StreamMessageType = "PodsUpdateResponse"
+	// Sent by the adapter as soon as the scheduler binds a pod
+	StreamBinding StreamMessageType = "Binding"
+	// Sent by the adapter as soon as the scheduler reports a pod as unschedulable
+	StreamFailure StreamMessageType = "Failure"
+	// Sent by the adapter as soon as a node is added by the cluster-autoscaler
+	StreamNewNode StreamMessageType = "NewNode"
+	// Sent by the adapter as soon as a node is removed by the cluster-autoscaler
+	StreamDeletedNode StreamMessageType = "DeletedNode"
+	// Sent by the adapter as soon as a pod is evicted
+	StreamEviction StreamMessageType = "Eviction"
+	// Sent by the adapter if a message of the simulation could not be processed
+	StreamError StreamMessageType = "Error"
+)
+
+// Information about an evicted pod
+type EvictionInformation struct {
+	Pod  string `json:"pod"`
+	Node string `json:"node"`
+}
+
+// Message on the bidirectional simulator stream. Exactly one of the
+// payload fields matching Type is set.
+type StreamMessage struct {
+	Type StreamMessageType `json:"type"`
+	// Id of the request, echoed in the corresponding response
+	Id string `json:"id,omitempty"`
+
+	NodeUpdate         *NodeUpdateRequest         `json:"nodeUpdate,omitempty"`
+	PodsUpdate         *PodsUpdateRequest         `json:"podsUpdate,omitempty"`
+	NodeDelta          *NodeDeltaRequest          `json:"nodeDelta,omitempty"`
+	PodsDelta          *PodsDeltaRequest          `json:"podsDelta,omitempty"`
+	NodeUpdateResponse *NodeUpdateResponse        `json:"nodeUpdateResponse,omitempty"`
+	PodsUpdateResponse *PodsUpdateResponse        `json:"podsUpdateResponse,omitempty"`
+	Binding            *BindingInformation        `json:"binding,omitempty"`
+	Failure            *BindingFailureInformation `json:"failure,omitempty"`
+	Node               *v1.Node                   `json:"node,omitempty"`
+	Eviction           *EvictionInformation       `json:"eviction,omitempty"`
+	Error              string                     `json:"error,omitempty"`
+}
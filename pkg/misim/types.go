@@ -7,49 +7,79 @@ import (
 	cluster "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
+// Version of the simulator API described by the types in this package
+const ApiVersion = "v1"
+
+// Path prefix of the versioned simulator API
+const ApiPrefix = "/sim/" + ApiVersion
+
 // Information about a successfully binded pod
 type BindingInformation struct {
-	Pod  string
-	Node string
+	Pod  string `json:"pod"`
+	Node string `json:"node"`
 }
 
 // Information about a failed binding for a pod
 type BindingFailureInformation struct {
-	Pod     string
-	Message string
+	Pod     string `json:"pod"`
+	Message string `json:"message"`
 }
 
 // Update request from the simulation for nodes
 type NodeUpdateRequest struct {
 	// All nodes the should be scheduled on the machines
-	AllNodes    v1.NodeList
-	Events      []metav1.WatchEvent
-	MachineSets []cluster.MachineSet
+	AllNodes    v1.NodeList          `json:"allNodes"`
+	Events      []metav1.WatchEvent  `json:"events,omitempty"`
+	MachineSets []cluster.MachineSet `json:"machineSets,omitempty"`
 	// Machines available for the nodes
-	Machines []cluster.Machine
+	Machines []cluster.Machine `json:"machines,omitempty"`
+}
+
+// Delta update request from the simulation for nodes. Only changed nodes are sent,
+// the adapter derives the watch events itself. Modified and deleted nodes have to
+// carry the resourceVersion last returned by the adapter (or none to skip the check).
+type NodeDeltaRequest struct {
+	Added    []v1.Node `json:"added,omitempty"`
+	Modified []v1.Node `json:"modified,omitempty"`
+	Deleted  []v1.Node `json:"deleted,omitempty"`
 }
 
 // Response of the adapter to a NodeUpdateRequest from the simulation
 type NodeUpdateResponse struct {
-	Data v1.NodeList `json:"Updated NodeList with"`
+	Data v1.NodeList `json:"data"`
 }
 
 // Update request from the simulation for pods
 type PodsUpdateRequest struct {
 	// All pods in the simulation
-	AllPods v1.PodList
-	Events  []metav1.WatchEvent
+	AllPods v1.PodList          `json:"allPods"`
+	Events  []metav1.WatchEvent `json:"events,omitempty"`
+	// Pods that still have to be placed
+	PodsToBePlaced v1.PodList `json:"podsToBePlaced"`
+	// Current simulation time, only used for the experiment results
+	SimTime *float64 `json:"simTime,omitempty"`
+}
+
+// Delta update request from the simulation for pods. Only changed pods are sent,
+// the adapter derives the watch events itself. Modified and deleted pods have to
+// carry the resourceVersion last returned by the adapter (or none to skip the check).
+type PodsDeltaRequest struct {
+	Added    []v1.Pod `json:"added,omitempty"`
+	Modified []v1.Pod `json:"modified,omitempty"`
+	Deleted  []v1.Pod `json:"deleted,omitempty"`
 	// Pods that still have to be placed
-	PodsToBePlaced v1.PodList
+	PodsToBePlaced v1.PodList `json:"podsToBePlaced"`
+	// Current simulation time, only used for the experiment results
+	SimTime *float64 `json:"simTime,omitempty"`
 }
 
 // Response of the adapter to a PodsUpdateRequest from the simulation
 // with the information about bindings and failures from the kubescheduler
 type PodsUpdateResponse struct {
-	Failed       []BindingFailureInformation
-	Binded       []BindingInformation
-	NewNodes     []v1.Node
-	DeletedNodes []v1.Node
+	Failed       []BindingFailureInformation `json:"failed"`
+	Binded       []BindingInformation        `json:"bound"`
+	NewNodes     []v1.Node                   `json:"newNodes"`
+	DeletedNodes []v1.Node                   `json:"deletedNodes"`
 }
 
 // Response of the adapter to a Events request from the simulation
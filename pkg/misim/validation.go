@@ -0,0 +1,191 @@
+package misim
+
+import (
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Problems found in a request of the simulation. Errors always reject the request,
+// warnings only do so in strict mode.
+type ValidationResult struct {
+	Errors   field.ErrorList
+	Warnings field.ErrorList
+}
+
+// Returns the problems that reject the request
+func (r ValidationResult) Rejecting(strict bool) field.ErrorList {
+	if strict {
+		return append(append(field.ErrorList{}, r.Errors...), r.Warnings...)
+	}
+	return r.Errors
+}
+
+func ValidateNodeUpdateRequest(u NodeUpdateRequest) ValidationResult {
+	var result ValidationResult
+	nodesPath := field.NewPath("allNodes", "items")
+	nodeNames := make(map[string]bool, len(u.AllNodes.Items))
+	for i, node := range u.AllNodes.Items {
+		path := nodesPath.Index(i)
+		result.Errors = append(result.Errors, validateName(path, node.Name, nodeNames)...)
+		if len(node.Status.Allocatable) == 0 {
+			result.Warnings = append(result.Warnings, field.Required(path.Child("status", "allocatable"), "nodes without allocatable resources cannot host pods"))
+		}
+	}
+	result.Warnings = append(result.Warnings, validateEvents(field.NewPath("events"), u.Events, nodeNames)...)
+
+	machineSetNames := make(map[string]bool, len(u.MachineSets))
+	for i, machineSet := range u.MachineSets {
+		path := field.NewPath("machineSets").Index(i)
+		result.Errors = append(result.Errors, validateName(path, machineSet.Name, machineSetNames)...)
+		if machineSet.Spec.Replicas == nil {
+			result.Errors = append(result.Errors, field.Required(path.Child("spec", "replicas"), ""))
+		}
+		if _, found := machineSet.Annotations["cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"]; !found {
+			result.Warnings = append(result.Warnings, field.Required(path.Child("metadata", "annotations").Key("cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"), "the machine set can never be scaled up"))
+		}
+	}
+	machineNames := make(map[string]bool, len(u.Machines))
+	for i, machine := range u.Machines {
+		path := field.NewPath("machines").Index(i)
+		result.Errors = append(result.Errors, validateName(path, machine.Name, machineNames)...)
+		if machine.Status.NodeRef != nil && !nodeNames[machine.Status.NodeRef.Name] {
+			result.Warnings = append(result.Warnings, field.NotFound(path.Child("status", "nodeRef", "name"), machine.Status.NodeRef.Name))
+		}
+	}
+	return result
+}
+
+func ValidateNodeDeltaRequest(u NodeDeltaRequest) ValidationResult {
+	var result ValidationResult
+	names := make(map[string]bool)
+	for _, list := range []struct {
+		path  *field.Path
+		nodes []v1.Node
+	}{{field.NewPath("added"), u.Added}, {field.NewPath("modified"), u.Modified}, {field.NewPath("deleted"), u.Deleted}} {
+		for i, node := range list.nodes {
+			result.Errors = append(result.Errors, validateName(list.path.Index(i), node.Name, names)...)
+		}
+	}
+	for i, node := range u.Added {
+		if len(node.Status.Allocatable) == 0 {
+			result.Warnings = append(result.Warnings, field.Required(field.NewPath("added").Index(i).Child("status", "allocatable"), "nodes without allocatable resources cannot host pods"))
+		}
+	}
+	return result
+}
+
+func ValidatePodsUpdateRequest(u PodsUpdateRequest) ValidationResult {
+	var result ValidationResult
+	podNames := make(map[string]bool, len(u.AllPods.Items))
+	for i, pod := range u.AllPods.Items {
+		result.Errors = append(result.Errors, validateName(field.NewPath("allPods", "items").Index(i), pod.Name, podNames)...)
+	}
+	result.Warnings = append(result.Warnings, validateEvents(field.NewPath("events"), u.Events, podNames)...)
+	result.Errors = append(result.Errors, validatePodsToBePlaced(u.PodsToBePlaced, podNames)...)
+	return result
+}
+
+// Whether the pods to be placed exist is checked by ValidatePodsToBePlacedExist once the delta is applied
+func ValidatePodsDeltaRequest(u PodsDeltaRequest) ValidationResult {
+	var result ValidationResult
+	names := make(map[string]bool)
+	for _, list := range []struct {
+		path *field.Path
+		pods []v1.Pod
+	}{{field.NewPath("added"), u.Added}, {field.NewPath("modified"), u.Modified}, {field.NewPath("deleted"), u.Deleted}} {
+		for i, pod := range list.pods {
+			result.Errors = append(result.Errors, validateName(list.path.Index(i), pod.Name, names)...)
+		}
+	}
+
+	placedNames := make(map[string]bool, len(u.PodsToBePlaced.Items))
+	for i, pod := range u.PodsToBePlaced.Items {
+		result.Errors = append(result.Errors, validateName(field.NewPath("podsToBePlaced", "items").Index(i), pod.Name, placedNames)...)
+	}
+	return result
+}
+
+// Returns an error for every pod to be placed that is not among pods, the pods resulting from a delta
+func ValidatePodsToBePlacedExist(podsToBePlaced v1.PodList, pods []v1.Pod) field.ErrorList {
+	podNames := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		podNames[pod.Name] = true
+	}
+	var errs field.ErrorList
+	for i, pod := range podsToBePlaced.Items {
+		if !podNames[pod.Name] {
+			errs = append(errs, field.NotFound(field.NewPath("podsToBePlaced", "items").Index(i).Child("metadata", "name"), pod.Name))
+		}
+	}
+	return errs
+}
+
+func validatePodsToBePlaced(podsToBePlaced v1.PodList, podNames map[string]bool) field.ErrorList {
+	var errs field.ErrorList
+	placedNames := make(map[string]bool, len(podsToBePlaced.Items))
+	for i, pod := range podsToBePlaced.Items {
+		path := field.NewPath("podsToBePlaced", "items").Index(i)
+		errs = append(errs, validateName(path, pod.Name, placedNames)...)
+		if pod.Name != "" && !podNames[pod.Name] {
+			errs = append(errs, field.NotFound(path.Child("metadata", "name"), pod.Name))
+		}
+	}
+	return errs
+}
+
+func validateName(path *field.Path, name string, seen map[string]bool) field.ErrorList {
+	namePath := path.Child("metadata", "name")
+	if name == "" {
+		return field.ErrorList{field.Required(namePath, "")}
+	}
+	if seen[name] {
+		return field.ErrorList{field.Duplicate(namePath, name)}
+	}
+	seen[name] = true
+	return nil
+}
+
+// Checks that the events agree with the list sent along with them
+func validateEvents(path *field.Path, events []metav1.WatchEvent, names map[string]bool) field.ErrorList {
+	var warnings field.ErrorList
+	for i, event := range events {
+		eventPath := path.Index(i)
+		name, ok := eventObjectName(event)
+		if !ok {
+			warnings = append(warnings, field.Invalid(eventPath.Child("object"), "", "the object of the event cannot be decoded"))
+			continue
+		}
+		switch event.Type {
+		case "ADDED", "MODIFIED":
+			if !names[name] {
+				warnings = append(warnings, field.NotFound(eventPath.Child("object", "metadata", "name"), name))
+			}
+		case "DELETED":
+			if names[name] {
+				warnings = append(warnings, field.Invalid(eventPath.Child("object", "metadata", "name"), name, "deleted object is still part of the list"))
+			}
+		default:
+			warnings = append(warnings, field.NotSupported(eventPath.Child("type"), event.Type, []string{"ADDED", "MODIFIED", "DELETED"}))
+		}
+	}
+	return warnings
+}
+
+func eventObjectName(event metav1.WatchEvent) (string, bool) {
+	if event.Object.Object != nil {
+		accessor, err := meta.Accessor(event.Object.Object)
+		if err != nil {
+			return "", false
+		}
+		return accessor.GetName(), true
+	}
+	var object metav1.PartialObjectMetadata
+	if err := json.Unmarshal(event.Object.Raw, &object); err != nil {
+		return "", false
+	}
+	return object.Name, true
+}
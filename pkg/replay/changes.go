@@ -0,0 +1,177 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-kube/pkg/audit"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Change of the cluster recorded in an audit log, exactly one of the pointers or names is set
+type Change struct {
+	Time time.Time
+	// Node created during the recording
+	AddedNode *v1.Node
+	// Node that existed before the recording, known from the full object of a request to it
+	ObservedNode *v1.Node
+	DeletedNode  string
+	AddedPod     *v1.Pod
+	// Name of the deleted pod in the adapter, see PodName
+	DeletedPod string
+	// Binding of a pod by the scheduler of the recorded cluster
+	Binding *Binding
+}
+
+type Binding struct {
+	// Name of the pod in the adapter, see PodName
+	Pod  string
+	Node string
+}
+
+// Numbers of the events of an audit log that could not be replayed
+type Skipped struct {
+	// Creates of pods and nodes that were logged below level Request, so their object is missing
+	WithoutObject int
+	// Events that could not be decoded
+	Invalid int
+}
+
+// Returns the name of a pod in the adapter, which serves the pods of all namespaces in the default namespace
+func PodName(namespace string, name string) string {
+	if namespace == "" || namespace == metav1.NamespaceDefault {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// Reads the changes of the audit logs in the order of their request times
+func ReadFiles(paths []string) ([]Change, Skipped, error) {
+	var changes []Change
+	var skipped Skipped
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, Skipped{}, err
+		}
+		fileChanges, fileSkipped, err := Read(file, path)
+		file.Close()
+		if err != nil {
+			return nil, Skipped{}, err
+		}
+		changes = append(changes, fileChanges...)
+		skipped.WithoutObject += fileSkipped.WithoutObject
+		skipped.Invalid += fileSkipped.Invalid
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Time.Before(changes[j].Time) })
+	return changes, skipped, nil
+}
+
+// Reads the changes of an audit log with one audit.k8s.io/v1 event per line
+func Read(r io.Reader, source string) ([]Change, Skipped, error) {
+	var changes []Change
+	var skipped Skipped
+	decoder := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var event audit.Event
+		err := decoder.Decode(&event)
+		if errors.Is(err, io.EOF) {
+			return changes, skipped, nil
+		}
+		if err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				// The decoder cannot continue after a syntax error
+				return nil, Skipped{}, fmt.Errorf("%s, event %d: %w", source, line, err)
+			}
+			skipped.Invalid++
+			continue
+		}
+		change, ok, err := changeOfEvent(event)
+		if err != nil {
+			skipped.Invalid++
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if change == nil {
+			skipped.WithoutObject++
+			continue
+		}
+		changes = append(changes, *change)
+	}
+}
+
+// Returns the change of the event, false if it changes nothing that is replayed,
+// and a nil change if the event lacks the object of the change
+func changeOfEvent(event audit.Event) (*Change, bool, error) {
+	ref := event.ObjectRef
+	// Only completed requests that succeeded changed the cluster
+	if event.Stage != audit.StageResponseComplete || ref == nil || ref.APIGroup != "" {
+		return nil, false, nil
+	}
+	if event.ResponseStatus == nil || event.ResponseStatus.Code < 200 || event.ResponseStatus.Code > 299 {
+		return nil, false, nil
+	}
+	change := &Change{Time: event.RequestReceivedTimestamp.Time}
+	switch {
+	case ref.Resource == "pods" && ref.Subresource == "" && event.Verb == "create":
+		var pod v1.Pod
+		if found, err := decodeObject(event, &pod); err != nil || !found {
+			return nil, true, err
+		}
+		if pod.Name == "" {
+			pod.Name = ref.Name
+		}
+		if pod.Namespace == "" {
+			pod.Namespace = ref.Namespace
+		}
+		change.AddedPod = &pod
+	case ref.Resource == "pods" && ref.Subresource == "binding" && event.Verb == "create":
+		var binding v1.Binding
+		if err := json.Unmarshal(event.RequestObject, &binding); err != nil || binding.Target.Name == "" {
+			return nil, true, nil
+		}
+		change.Binding = &Binding{Pod: PodName(ref.Namespace, ref.Name), Node: binding.Target.Name}
+	case ref.Resource == "pods" && ref.Subresource == "" && event.Verb == "delete":
+		change.DeletedPod = PodName(ref.Namespace, ref.Name)
+	case ref.Resource == "nodes" && ref.Subresource == "" && event.Verb == "create":
+		var node v1.Node
+		if found, err := decodeObject(event, &node); err != nil || !found {
+			return nil, true, err
+		}
+		change.AddedNode = &node
+	case ref.Resource == "nodes" && (ref.Subresource == "" || ref.Subresource == "status") && (event.Verb == "update" || event.Verb == "patch"):
+		// Only the response holds the complete node, patches are partial
+		var node v1.Node
+		if len(event.ResponseObject) == 0 || json.Unmarshal(event.ResponseObject, &node) != nil || node.Name == "" {
+			return nil, false, nil
+		}
+		change.ObservedNode = &node
+	case ref.Resource == "nodes" && ref.Subresource == "" && event.Verb == "delete":
+		change.DeletedNode = ref.Name
+	default:
+		return nil, false, nil
+	}
+	return change, true, nil
+}
+
+// Decodes the response object, which carries the defaults and generated name, or else the request object.
+// Returns false if the event has neither.
+func decodeObject(event audit.Event, into any) (bool, error) {
+	raw := event.ResponseObject
+	if len(raw) == 0 {
+		raw = event.RequestObject
+	}
+	if len(raw) == 0 {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, into)
+}
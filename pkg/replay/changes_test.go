@@ -0,0 +1,86 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Audit log of a recorded cluster, one event per line
+const auditLog = `{"stage":"RequestReceived","verb":"create","objectRef":{"resource":"pods","namespace":"team","apiVersion":"v1"},"requestReceivedTimestamp":"2023-06-01T10:00:00.000000Z"}
+{"stage":"ResponseComplete","verb":"create","objectRef":{"resource":"pods","namespace":"team","apiVersion":"v1"},"responseStatus":{"code":201},"requestObject":{"metadata":{"generateName":"web-"}},"responseObject":{"metadata":{"name":"web-1","namespace":"team"}},"requestReceivedTimestamp":"2023-06-01T10:00:00.000000Z"}
+{"stage":"ResponseComplete","verb":"create","objectRef":{"resource":"pods","namespace":"team","apiVersion":"v1"},"responseStatus":{"code":201},"requestReceivedTimestamp":"2023-06-01T10:00:01.000000Z"}
+{"stage":"ResponseComplete","verb":"create","objectRef":{"resource":"pods","namespace":"team","name":"web-1","apiVersion":"v1","subresource":"binding"},"responseStatus":{"code":201},"requestObject":{"metadata":{"name":"web-1"},"target":{"kind":"Node","name":"node-1"}},"requestReceivedTimestamp":"2023-06-01T10:00:02.000000Z"}
+{"stage":"ResponseComplete","verb":"create","objectRef":{"resource":"pods","namespace":"team","apiVersion":"v1"},"responseStatus":{"code":409},"requestObject":{"metadata":{"name":"web-1"}},"requestReceivedTimestamp":"2023-06-01T10:00:03.000000Z"}
+{"stage":"ResponseComplete","verb":"delete","objectRef":{"resource":"pods","namespace":"default","name":"old","apiVersion":"v1"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2023-06-01T10:00:04.000000Z"}
+{"stage":"ResponseComplete","verb":"delete","objectRef":{"resource":"pods","namespace":"team","name":"gone","apiVersion":"v1"},"responseStatus":{"code":404},"requestReceivedTimestamp":"2023-06-01T10:00:04.500000Z"}
+{"stage":"ResponseComplete","verb":"create","objectRef":{"resource":"nodes","apiVersion":"v1"},"responseStatus":{"code":201},"requestObject":{"metadata":{"name":"node-2"}},"requestReceivedTimestamp":"2023-06-01T10:00:05.000000Z"}
+{"stage":"ResponseComplete","verb":"patch","objectRef":{"resource":"nodes","name":"node-1","apiVersion":"v1","subresource":"status"},"responseStatus":{"code":200},"responseObject":{"metadata":{"name":"node-1"},"status":{"capacity":{"cpu":"4"}}},"requestReceivedTimestamp":"2023-06-01T10:00:06.000000Z"}
+{"stage":"ResponseComplete","verb":"patch","objectRef":{"resource":"nodes","name":"node-1","apiVersion":"v1"},"responseStatus":{"code":200},"requestObject":{"metadata":{"labels":{"a":"b"}}},"requestReceivedTimestamp":"2023-06-01T10:00:07.000000Z"}
+{"stage":"ResponseComplete","verb":"create","objectRef":{"resource":"deployments","namespace":"team","apiGroup":"apps","apiVersion":"v1"},"responseStatus":{"code":201},"requestReceivedTimestamp":"2023-06-01T10:00:08.000000Z"}
+{"stage":"ResponseComplete","verb":"create","objectRef":{"resource":"nodes","apiVersion":"v1"},"responseStatus":{"code":201},"requestObject":{"metadata":{"name":7}},"requestReceivedTimestamp":"2023-06-01T10:00:09.000000Z"}
+{"stage":5}
+{"stage":"ResponseComplete","verb":"delete","objectRef":{"resource":"nodes","name":"node-2","apiVersion":"v1"},"responseStatus":{"code":200},"requestReceivedTimestamp":"2023-06-01T10:00:10.000000Z"}
+`
+
+func TestRead(t *testing.T) {
+	changes, skipped, err := Read(strings.NewReader(auditLog), "audit.log")
+	if err != nil {
+		t.Fatalf("Read returned error %v", err)
+	}
+	// The pod create logged at level Metadata lacks its object
+	if skipped.WithoutObject != 1 {
+		t.Fatalf("Read skipped %d events without object, want 1", skipped.WithoutObject)
+	}
+	// The node with a number as name and the event with a number as stage
+	if skipped.Invalid != 2 {
+		t.Fatalf("Read skipped %d invalid events, want 2", skipped.Invalid)
+	}
+	if len(changes) != 6 {
+		t.Fatalf("Read returned %d changes, want 6: %+v", len(changes), changes)
+	}
+
+	// The generated name is only in the response object
+	if pod := changes[0].AddedPod; pod == nil || pod.Name != "web-1" || pod.Namespace != "team" {
+		t.Fatalf("first change is %+v, want the created pod team/web-1", changes[0])
+	}
+	if want := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC); !changes[0].Time.Equal(want) {
+		t.Fatalf("first change happened at %v, want %v", changes[0].Time, want)
+	}
+	// Pods of other namespaces are addressed by their name in the adapter
+	if binding := changes[1].Binding; binding == nil || *binding != (Binding{Pod: "team.web-1", Node: "node-1"}) {
+		t.Fatalf("second change is %+v, want the binding of team.web-1 to node-1", changes[1])
+	}
+	if changes[2].DeletedPod != "old" {
+		t.Fatalf("third change is %+v, want the delete of pod old", changes[2])
+	}
+	if node := changes[3].AddedNode; node == nil || node.Name != "node-2" {
+		t.Fatalf("fourth change is %+v, want the created node node-2", changes[3])
+	}
+	// Only the complete node of the status patch is observed, not the partial patch without response
+	if node := changes[4].ObservedNode; node == nil || node.Name != "node-1" || node.Status.Capacity.Cpu().String() != "4" {
+		t.Fatalf("fifth change is %+v, want the observed node node-1", changes[4])
+	}
+	if changes[5].DeletedNode != "node-2" {
+		t.Fatalf("sixth change is %+v, want the delete of node node-2", changes[5])
+	}
+}
+
+func TestReadSyntaxError(t *testing.T) {
+	log := strings.SplitAfter(auditLog, "\n")[1] + "{\"stage\":\n}\n"
+	if _, _, err := Read(strings.NewReader(log), "audit.log"); err == nil || !strings.Contains(err.Error(), "audit.log, event 2") {
+		t.Fatalf("Read returned error %v, want a syntax error in event 2", err)
+	}
+}
+
+func TestPodName(t *testing.T) {
+	for _, test := range []struct{ namespace, name, want string }{
+		{"", "a", "a"},
+		{"default", "a", "a"},
+		{"team", "a", "team.a"},
+	} {
+		if got := PodName(test.namespace, test.name); got != test.want {
+			t.Errorf("PodName(%q, %q) = %q, want %q", test.namespace, test.name, got, test.want)
+		}
+	}
+}
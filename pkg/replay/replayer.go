@@ -0,0 +1,275 @@
+package replay
+
+import (
+	"context"
+	"go-kube/pkg/misim"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+type Options struct {
+	// Replay speed relative to the recording, e.g. 10 for ten times faster. 0 replays without waiting.
+	Speed float64
+	// Changes within this recorded time are sent together, the pods created in it are placed in one round
+	Batch time.Duration
+	// Replays the recorded node creates and deletes. Without, the nodes are left to the cluster
+	// autoscaler in the loop and to the nodes loaded into the adapter beforehand.
+	ReplayNodes bool
+}
+
+// Placement of a pod in the recording and in the replay
+type PodComparison struct {
+	Pod          string `json:"pod"`
+	RecordedNode string `json:"recordedNode,omitempty"`
+	LiveNode     string `json:"liveNode,omitempty"`
+	// Message of the last failed scheduling attempt in the replay
+	LiveFailure string `json:"liveFailure,omitempty"`
+}
+
+type Summary struct {
+	Rounds int `json:"rounds"`
+	Pods   int `json:"pods"`
+	// Pods bound to the same node as in the recording, and to another one
+	SameNode      int `json:"sameNode"`
+	DifferentNode int `json:"differentNode"`
+	// Pods bound in only one of recording and replay
+	BoundOnlyRecorded int `json:"boundOnlyRecorded"`
+	BoundOnlyLive     int `json:"boundOnlyLive"`
+	// Node changes of the recording and of the cluster autoscaler in the replay
+	RecordedNodesAdded   int `json:"recordedNodesAdded"`
+	RecordedNodesDeleted int `json:"recordedNodesDeleted"`
+	LiveNodesAdded       int `json:"liveNodesAdded"`
+	LiveNodesDeleted     int `json:"liveNodesDeleted"`
+}
+
+// Feeds the changes of an audit log into the adapter through the simulator API in sync mode
+type Replayer struct {
+	client  *misim.Client
+	options Options
+
+	comparisons map[string]*PodComparison
+	// Counters of rounds and nodes, the placements are counted by Summary
+	summary Summary
+}
+
+func NewReplayer(client *misim.Client, options Options) *Replayer {
+	return &Replayer{client: client, options: options, comparisons: make(map[string]*PodComparison)}
+}
+
+// Replays the changes, which have to be ordered by time. Every batch of changes is applied to the
+// nodes and pods fetched from the adapter, so that the bindings and node changes of the live
+// components are kept. New pods and pods that are still pending are placed in the round of the batch.
+func (r *Replayer) Run(ctx context.Context, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	recordingStart, replayStart := changes[0].Time, time.Now()
+	for start := 0; start < len(changes); {
+		end := start + 1
+		for end < len(changes) && changes[end].Time.Sub(changes[start].Time) < r.options.Batch {
+			end++
+		}
+		batch := changes[start:end]
+		start = end
+
+		recorded := batch[0].Time.Sub(recordingStart)
+		if r.options.Speed > 0 {
+			due := replayStart.Add(time.Duration(float64(recorded) / r.options.Speed))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Until(due)):
+			}
+		}
+		if err := r.replayNodes(ctx, batch); err != nil {
+			return err
+		}
+		if err := r.replayPods(ctx, batch, recorded.Seconds()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Replayer) replayNodes(ctx context.Context, batch []Change) error {
+	if !r.options.ReplayNodes {
+		return nil
+	}
+	var added []v1.Node
+	deleted := make(map[string]bool)
+	for _, change := range batch {
+		switch {
+		case change.AddedNode != nil:
+			added = append(added, *change.AddedNode)
+			r.summary.RecordedNodesAdded++
+		case change.ObservedNode != nil:
+			added = append(added, *change.ObservedNode)
+		case change.DeletedNode != "":
+			deleted[change.DeletedNode] = true
+			r.summary.RecordedNodesDeleted++
+		}
+	}
+	if len(added) == 0 && len(deleted) == 0 {
+		return nil
+	}
+	nodes, err := r.client.GetNodes(ctx)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(nodes.Items))
+	remaining := nodes.Items[:0]
+	for _, node := range nodes.Items {
+		if !deleted[node.Name] {
+			remaining = append(remaining, node)
+			existing[node.Name] = true
+		}
+	}
+	changed := len(remaining) != len(nodes.Items)
+	for _, node := range added {
+		// Observed nodes are only added once, created nodes replace a stale one of the same name
+		if existing[node.Name] || deleted[node.Name] {
+			continue
+		}
+		existing[node.Name] = true
+		remaining = append(remaining, cleanNode(node))
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	nodes.Items = remaining
+	klog.V(2).Infof("Replaying %d nodes", len(nodes.Items))
+	_, err = r.client.SyncNodes(ctx, misim.NodeUpdateRequest{AllNodes: nodes})
+	return err
+}
+
+func (r *Replayer) replayPods(ctx context.Context, batch []Change, simTime float64) error {
+	var added []v1.Pod
+	deleted := make(map[string]bool)
+	for _, change := range batch {
+		switch {
+		case change.AddedPod != nil:
+			pod := cleanPod(*change.AddedPod)
+			added = append(added, pod)
+			comparison := r.comparison(pod.Name)
+			if pod.Spec.NodeName != "" {
+				// e.g. pods of daemon sets, which the scheduler never sees
+				comparison.RecordedNode, comparison.LiveNode = pod.Spec.NodeName, pod.Spec.NodeName
+			}
+		case change.DeletedPod != "":
+			deleted[change.DeletedPod] = true
+		case change.Binding != nil:
+			r.comparison(change.Binding.Pod).RecordedNode = change.Binding.Node
+		}
+	}
+	if len(added) == 0 && len(deleted) == 0 {
+		return nil
+	}
+	pods, err := r.client.GetPods(ctx)
+	if err != nil {
+		return err
+	}
+	remaining := pods.Items[:0]
+	for _, pod := range pods.Items {
+		if !deleted[pod.Name] {
+			remaining = append(remaining, pod)
+		}
+	}
+	existing := make(map[string]bool, len(remaining))
+	for _, pod := range remaining {
+		existing[pod.Name] = true
+	}
+	for _, pod := range added {
+		if !existing[pod.Name] && !deleted[pod.Name] {
+			existing[pod.Name] = true
+			remaining = append(remaining, pod)
+		}
+	}
+	pods.Items = remaining
+	toBePlaced := v1.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}}
+	for _, pod := range pods.Items {
+		// Pods that failed before are placed again, like the scheduler retries them
+		if pod.Spec.NodeName == "" {
+			toBePlaced.Items = append(toBePlaced.Items, pod)
+		}
+	}
+
+	klog.V(2).Infof("Replaying %d pods, %d to be placed", len(pods.Items), len(toBePlaced.Items))
+	response, err := r.client.SyncPods(ctx, misim.PodsUpdateRequest{AllPods: pods, PodsToBePlaced: toBePlaced, SimTime: &simTime})
+	if err != nil {
+		return err
+	}
+	if len(toBePlaced.Items) > 0 {
+		r.summary.Rounds++
+	}
+	for _, binding := range response.Binded {
+		comparison := r.comparison(binding.Pod)
+		comparison.LiveNode, comparison.LiveFailure = binding.Node, ""
+	}
+	for _, failure := range response.Failed {
+		r.comparison(failure.Pod).LiveFailure = failure.Message
+	}
+	r.summary.LiveNodesAdded += len(response.NewNodes)
+	r.summary.LiveNodesDeleted += len(response.DeletedNodes)
+	klog.V(1).Infof("Round at %.1fs: %d bound, %d failed", simTime, len(response.Binded), len(response.Failed))
+	return nil
+}
+
+func (r *Replayer) comparison(pod string) *PodComparison {
+	comparison, found := r.comparisons[pod]
+	if !found {
+		comparison = &PodComparison{Pod: pod}
+		r.comparisons[pod] = comparison
+	}
+	return comparison
+}
+
+// Returns the placement of every pod in recording and replay, ordered by pod
+func (r *Replayer) Comparisons() []PodComparison {
+	comparisons := make([]PodComparison, 0, len(r.comparisons))
+	for _, comparison := range r.comparisons {
+		comparisons = append(comparisons, *comparison)
+	}
+	sort.Slice(comparisons, func(i, j int) bool { return comparisons[i].Pod < comparisons[j].Pod })
+	return comparisons
+}
+
+// Returns the summary of the changes replayed so far
+func (r *Replayer) Summary() Summary {
+	summary := r.summary
+	summary.Pods = len(r.comparisons)
+	for _, comparison := range r.comparisons {
+		switch {
+		case comparison.RecordedNode != "" && comparison.LiveNode != "":
+			if comparison.RecordedNode == comparison.LiveNode {
+				summary.SameNode++
+			} else {
+				summary.DifferentNode++
+			}
+		case comparison.RecordedNode != "":
+			summary.BoundOnlyRecorded++
+		case comparison.LiveNode != "":
+			summary.BoundOnlyLive++
+		}
+	}
+	return summary
+}
+
+// Returns the pod as it would be created in the adapter, in the default namespace and without server-set fields
+func cleanPod(pod v1.Pod) v1.Pod {
+	pod.Name = PodName(pod.Namespace, pod.Name)
+	pod.Namespace = metav1.NamespaceDefault
+	pod.ResourceVersion, pod.UID, pod.ManagedFields = "", "", nil
+	pod.OwnerReferences = nil
+	pod.Status = v1.PodStatus{Phase: v1.PodPending}
+	return pod
+}
+
+func cleanNode(node v1.Node) v1.Node {
+	node.ResourceVersion, node.UID, node.ManagedFields = "", "", nil
+	return node
+}
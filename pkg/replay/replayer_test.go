@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"go-kube/pkg/misim"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func podNames(pods []v1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
+func TestReplayPods(t *testing.T) {
+	changes, _, err := Read(strings.NewReader(auditLog), "audit.log")
+	if err != nil {
+		t.Fatalf("Read returned error %v", err)
+	}
+
+	// The adapter holds a pod that is deleted by the recording and one that failed to be placed before
+	stored := v1.PodList{Items: []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "old", Namespace: "default"}, Spec: v1.PodSpec{NodeName: "node-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "default"}},
+	}}
+	var requests []misim.PodsUpdateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/api/v1/pods?":
+			json.NewEncoder(w).Encode(stored)
+		case misim.ApiPrefix + "/updatePods?mode=sync":
+			var request misim.PodsUpdateRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Errorf("unable to decode the pods update: %v", err)
+			}
+			requests = append(requests, request)
+			json.NewEncoder(w).Encode(misim.PodsUpdateResponse{
+				Binded: []misim.BindingInformation{{Pod: "team.web-1", Node: "node-2"}},
+				Failed: []misim.BindingFailureInformation{{Pod: "pending", Message: "no fit"}},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	replayer := NewReplayer(misim.NewClient(server.URL), Options{})
+	if err := replayer.replayPods(context.Background(), changes, 12.5); err != nil {
+		t.Fatalf("replayPods returned error %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("adapter received %d pods updates, want 1", len(requests))
+	}
+	request := requests[0]
+	if names := podNames(request.AllPods.Items); !reflect.DeepEqual(names, []string{"pending", "team.web-1"}) {
+		t.Fatalf("replayPods synced pods %v", names)
+	}
+	if created := request.AllPods.Items[1]; created.Namespace != metav1.NamespaceDefault || created.Status.Phase != v1.PodPending {
+		t.Fatalf("replayPods created pod %s in namespace %s with phase %s", created.Name, created.Namespace, created.Status.Phase)
+	}
+	if names := podNames(request.PodsToBePlaced.Items); !reflect.DeepEqual(names, []string{"pending", "team.web-1"}) {
+		t.Fatalf("replayPods placed pods %v", names)
+	}
+	if request.SimTime == nil || *request.SimTime != 12.5 {
+		t.Fatalf("replayPods sent simulation time %v, want 12.5", request.SimTime)
+	}
+
+	wantComparisons := []PodComparison{
+		{Pod: "pending", LiveFailure: "no fit"},
+		{Pod: "team.web-1", RecordedNode: "node-1", LiveNode: "node-2"},
+	}
+	if comparisons := replayer.Comparisons(); !reflect.DeepEqual(comparisons, wantComparisons) {
+		t.Fatalf("replayer compared %+v, want %+v", comparisons, wantComparisons)
+	}
+	wantSummary := Summary{Rounds: 1, Pods: 2, DifferentNode: 1}
+	if summary := replayer.Summary(); summary != wantSummary {
+		t.Fatalf("replayer summarized %+v, want %+v", summary, wantSummary)
+	}
+
+	// A batch of only bindings does not start a round
+	if err := replayer.replayPods(context.Background(), changes[1:2], 13); err != nil {
+		t.Fatalf("replayPods returned error %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("adapter received %d pods updates, want no new one", len(requests))
+	}
+}
@@ -0,0 +1,127 @@
+package results
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File format of the results
+type Format string
+
+const (
+	// One row per round, lists are separated by semicolons
+	CSV Format = "csv"
+	// One JSON object per line and round
+	JSONL Format = "jsonl"
+)
+
+var csvHeader = []string{"round", "simTime", "time", "placed", "bound", "failed", "failureMessages", "newNodes", "deletedNodes", "machineSetReplicas", "clusterAutoscalerActive", "clusterAutoscalerStatus"}
+
+// Appends a record for every round to a results file. Records are written
+// immediately, so the file can be followed while the experiment runs.
+type Recorder struct {
+	mu     sync.Mutex
+	round  int
+	file   *os.File
+	encode func(Round) error
+}
+
+// Opens the results file for appending, it is created if it does not exist
+func Open(path string, format Format) (*Recorder, error) {
+	if format != CSV && format != JSONL {
+		return nil, fmt.Errorf("unknown results format %q, expected %q or %q", format, CSV, JSONL)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	recorder := &Recorder{file: file}
+	if format == CSV {
+		writer := csv.NewWriter(file)
+		// Appended rows must not repeat the header
+		if info.Size() == 0 {
+			writer.Write(csvHeader)
+		}
+		recorder.encode = func(round Round) error {
+			writer.Write(csvRow(round))
+			writer.Flush()
+			return writer.Error()
+		}
+	} else {
+		encoder := json.NewEncoder(file)
+		recorder.encode = func(round Round) error {
+			return encoder.Encode(round)
+		}
+	}
+	return recorder, nil
+}
+
+// Numbers the round and appends it to the file
+func (r *Recorder) Record(round Round) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.round++
+	round.Round = r.round
+	return r.encode(round)
+}
+
+// Writes the file to disk and closes it
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	syncErr := r.file.Sync()
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	return syncErr
+}
+
+func csvRow(round Round) []string {
+	simTime := ""
+	if round.SimTime != nil {
+		simTime = strconv.FormatFloat(*round.SimTime, 'f', -1, 64)
+	}
+	bound := make([]string, len(round.Bound))
+	for i, binding := range round.Bound {
+		bound[i] = binding.Pod + "=" + binding.Node
+	}
+	failed := make([]string, len(round.Failed))
+	// Messages may contain any character, so they are written as JSON object keyed by pod
+	messages := make(map[string]string, len(round.Failed))
+	for i, failure := range round.Failed {
+		failed[i] = failure.Pod
+		messages[failure.Pod] = failure.Message
+	}
+	encodedMessages, _ := json.Marshal(messages)
+	machineSets := make([]string, 0, len(round.MachineSetReplicas))
+	for name, replicas := range round.MachineSetReplicas {
+		machineSets = append(machineSets, name+"="+strconv.Itoa(int(replicas)))
+	}
+	sort.Strings(machineSets)
+	return []string{
+		strconv.Itoa(round.Round),
+		simTime,
+		round.Time.Format(time.RFC3339Nano),
+		strings.Join(round.Placed, ";"),
+		strings.Join(bound, ";"),
+		strings.Join(failed, ";"),
+		string(encodedMessages),
+		strings.Join(round.NewNodes, ";"),
+		strings.Join(round.DeletedNodes, ";"),
+		strings.Join(machineSets, ";"),
+		strconv.FormatBool(round.ClusterAutoscalerActive),
+		round.ClusterAutoscalerStatus,
+	}
+}
@@ -0,0 +1,27 @@
+package results
+
+import (
+	"go-kube/pkg/misim"
+	"time"
+)
+
+// Outcome of a simulator round, i.e. of a pods update
+type Round struct {
+	// Number of the round since the adapter started, starting at 1
+	Round int `json:"round"`
+	// Simulation time sent by the simulator, nil if it sent none
+	SimTime *float64 `json:"simTime,omitempty"`
+	// Wall clock time at which the round finished
+	Time time.Time `json:"time"`
+	// Names of the pods to be placed in this round
+	Placed       []string                          `json:"placed"`
+	Bound        []misim.BindingInformation        `json:"bound"`
+	Failed       []misim.BindingFailureInformation `json:"failed"`
+	NewNodes     []string                          `json:"newNodes"`
+	DeletedNodes []string                          `json:"deletedNodes"`
+	// Desired replicas per machine set at the end of the round
+	MachineSetReplicas      map[string]int32 `json:"machineSetReplicas"`
+	ClusterAutoscalerActive bool             `json:"clusterAutoscalerActive"`
+	// Status reported by the cluster-autoscaler in its status config map
+	ClusterAutoscalerStatus string `json:"clusterAutoscalerStatus"`
+}
@@ -3,6 +3,8 @@ package storage
 type IdStorage interface {
 	GetNextId() int
 	StoreNextId(id int)
+	// Restarts the ids at 1
+	Reset()
 }
 
 type AdapterStateStorage interface {
@@ -10,4 +12,6 @@ type AdapterStateStorage interface {
 	IsClusterAutoscalerActive() bool
 	StoreClusterAutoscalingDone(done bool)
 	IsClusterAutoscalingDone() bool
+	// Restores the state of a freshly started adapter
+	Reset()
 }
@@ -0,0 +1,12 @@
+package storage
+
+import "go-kube/pkg/admin"
+
+// Requests to the API of the cluster. Like the components it is not reset between experiments.
+type ApiUsageStorage interface {
+	// Records a request of the component with the passed User-Agent, support is one of the admin.Route constants
+	RecordRequest(userAgent string, verb string, path string, support string, status int)
+	GetApiUsage() admin.ApiUsageReport
+	// Removes all recorded requests
+	Clear()
+}
@@ -1,6 +1,9 @@
 package storage
 
 type ChannelWrapper[T any] interface {
+	// Returns a new channel for the answer of a round, the previous one is no longer sent to
 	InitChannel() chan T
-	Get() chan T
+	// Sends the answer of the current round without blocking. Returns false if the round
+	// has already been answered or no round was started, the value is discarded then.
+	Send(value T) bool
 }
@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"go-kube/internal/broadcast"
+	"go-kube/pkg/misim"
+)
+
+// Components connected to the cluster. It is not reset between experiments,
+// as the components stay connected.
+type ComponentStorage interface {
+	// Records a request of the component with the passed User-Agent
+	Seen(userAgent string)
+	// Records an open watch of the resource, the returned function records its end
+	WatchStarted(userAgent string, resource string) func()
+	// Returns the components seen so far, the broadcaster sends a component whenever its watches change
+	GetComponents() ([]misim.Component, *broadcast.BroadcastServer[misim.Component])
+}
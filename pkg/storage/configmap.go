@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"go-kube/internal/broadcast"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Config maps loaded from manifests, the status of the cluster autoscaler is kept in the StatusConfigMapStorage
+type ConfigMapStorage interface {
+	// Stores a config map list in the storage
+	StoreConfigMaps(cms v1.ConfigMapList, events []metav1.WatchEvent)
+	// Returns the current config maps of all namespaces
+	GetConfigMaps() (v1.ConfigMapList, *broadcast.BroadcastServer[metav1.WatchEvent])
+	// Returns the config map with the passed name in the passed namespace, false if there is none
+	GetConfigMap(namespace string, name string) (v1.ConfigMap, bool)
+	// Removes all config maps, a DELETED event is sent for every config map.
+	// Returns the number of removed config maps.
+	Reset() int
+}
@@ -11,4 +11,7 @@ type DaemonSetStorage interface {
 	StoreDaemonSets(ds v1.DaemonSetList, events []metav1.WatchEvent)
 	// Returns the current daemonsets
 	GetDaemonSets() (v1.DaemonSetList, *broadcast.BroadcastServer[metav1.WatchEvent])
+	// Removes all daemonsets, a DELETED event is sent for every daemonset.
+	// Returns the number of removed daemonsets.
+	Reset() int
 }
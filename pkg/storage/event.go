@@ -10,4 +10,6 @@ type EventStorage interface {
 	GetEventsApiEvents() eventsv1.EventList
 	StoreCoreApiEvent(event v1.Event) v1.Event
 	GetCoreApiEvents() v1.EventList
+	// Removes the events of both APIs, returns their number
+	Reset() int
 }
@@ -0,0 +1,9 @@
+package storage
+
+import "go-kube/pkg/faults"
+
+// Faults injected into the Kubernetes API of the cluster. They are kept when the cluster is reset.
+type FaultStorage interface {
+	GetFaults() *faults.Injector
+	StoreFaults(injector *faults.Injector)
+}
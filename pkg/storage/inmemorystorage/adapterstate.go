@@ -1,17 +1,30 @@
 package inmemorystorage
 
+import "sync"
+
 type IdInMemoryStorage struct {
+	mu     sync.Mutex
 	nextId int
 }
 
 func (s *IdInMemoryStorage) GetNextId() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.nextId
 }
 
 func (s *IdInMemoryStorage) StoreNextId(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.nextId = id
 }
 
+func (s *IdInMemoryStorage) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextId = 1
+}
+
 func NewIdInMemoryStorage() IdInMemoryStorage {
 	return IdInMemoryStorage{
 		nextId: 1,
@@ -19,26 +32,42 @@ func NewIdInMemoryStorage() IdInMemoryStorage {
 }
 
 type AdapterStateInMemoryStorage struct {
+	mu                      sync.RWMutex
 	clusterAutoscalerActive bool
 	clusterAutoscalingDone  bool
 }
 
 func (s *AdapterStateInMemoryStorage) StoreClusterAutoscalerActive(active bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.clusterAutoscalerActive = active
 }
 
 func (s *AdapterStateInMemoryStorage) IsClusterAutoscalerActive() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.clusterAutoscalerActive
 }
 
 func (s *AdapterStateInMemoryStorage) StoreClusterAutoscalingDone(done bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.clusterAutoscalingDone = done
 }
 
 func (s *AdapterStateInMemoryStorage) IsClusterAutoscalingDone() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.clusterAutoscalingDone
 }
 
+func (s *AdapterStateInMemoryStorage) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clusterAutoscalerActive = false
+	s.clusterAutoscalingDone = true
+}
+
 func NewAdapterStateInMemoryStorage() AdapterStateInMemoryStorage {
 	return AdapterStateInMemoryStorage{
 		clusterAutoscalerActive: false,
@@ -0,0 +1,129 @@
+package inmemorystorage
+
+import (
+	"fmt"
+	"go-kube/pkg/admin"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Different calls recorded per component, unknown routes are recorded with their path,
+// which may contain object names
+const maxCallsPerComponent = 1000
+
+type apiCallKey struct {
+	verb string
+	path string
+}
+
+type componentApiUsage struct {
+	usage admin.ComponentApiUsage
+	calls map[apiCallKey]*admin.ApiCallUsage
+}
+
+type ApiUsageInMemoryStorage struct {
+	mu    sync.Mutex
+	since time.Time
+	// Requests by User-Agent
+	components map[string]*componentApiUsage
+}
+
+// ApiUsageStorage interface
+
+func (s *ApiUsageInMemoryStorage) RecordRequest(userAgent string, verb string, path string, support string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	component, found := s.components[userAgent]
+	if !found {
+		name, _, _ := strings.Cut(userAgent, "/")
+		component = &componentApiUsage{
+			usage: admin.ComponentApiUsage{Name: name, UserAgent: userAgent},
+			calls: make(map[apiCallKey]*admin.ApiCallUsage),
+		}
+		s.components[userAgent] = component
+	}
+	component.usage.Requests++
+	key := apiCallKey{verb: verb, path: path}
+	call, found := component.calls[key]
+	if !found {
+		if len(component.calls) >= maxCallsPerComponent {
+			component.usage.OtherRequests++
+			return
+		}
+		call = &admin.ApiCallUsage{Verb: verb, Path: path, Statuses: make(map[int]int)}
+		component.calls[key] = call
+	}
+	// A route served by a stubbed API group is unsupported, even if it was recorded as supported before
+	if support != admin.RouteSupported || call.Support == "" {
+		call.Support = support
+	}
+	call.Count++
+	call.Statuses[status]++
+	call.LastSeen = time.Now()
+}
+
+func (s *ApiUsageInMemoryStorage) GetApiUsage() admin.ApiUsageReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	report := admin.ApiUsageReport{
+		Since:       s.since,
+		Components:  make([]admin.ComponentApiUsage, 0, len(s.components)),
+		Unsupported: []string{},
+		Unknown:     []string{},
+	}
+	unsupported := make(map[string]bool)
+	unknown := make(map[string]bool)
+	for _, component := range s.components {
+		usage := component.usage
+		usage.Calls = make([]admin.ApiCallUsage, 0, len(component.calls))
+		for _, call := range component.calls {
+			copied := *call
+			copied.Statuses = make(map[int]int, len(call.Statuses))
+			for status, count := range call.Statuses {
+				copied.Statuses[status] = count
+			}
+			usage.Calls = append(usage.Calls, copied)
+			switch call.Support {
+			case admin.RouteUnsupported:
+				unsupported[fmt.Sprintf("%s %s", call.Verb, call.Path)] = true
+			case admin.RouteUnknown:
+				unknown[fmt.Sprintf("%s %s", call.Verb, call.Path)] = true
+			}
+		}
+		sort.Slice(usage.Calls, func(i, j int) bool {
+			if usage.Calls[i].Path != usage.Calls[j].Path {
+				return usage.Calls[i].Path < usage.Calls[j].Path
+			}
+			return usage.Calls[i].Verb < usage.Calls[j].Verb
+		})
+		report.Components = append(report.Components, usage)
+	}
+	sort.Slice(report.Components, func(i, j int) bool { return report.Components[i].UserAgent < report.Components[j].UserAgent })
+	for call := range unsupported {
+		report.Unsupported = append(report.Unsupported, call)
+	}
+	for call := range unknown {
+		report.Unknown = append(report.Unknown, call)
+	}
+	sort.Strings(report.Unsupported)
+	sort.Strings(report.Unknown)
+	return report
+}
+
+func (s *ApiUsageInMemoryStorage) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.since = time.Now()
+	s.components = make(map[string]*componentApiUsage)
+}
+
+// Constructors
+
+func NewApiUsageInMemoryStorage() ApiUsageInMemoryStorage {
+	return ApiUsageInMemoryStorage{
+		since:      time.Now(),
+		components: make(map[string]*componentApiUsage),
+	}
+}
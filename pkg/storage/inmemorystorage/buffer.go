@@ -1,18 +1,31 @@
 package inmemorystorage
 
+import "sync"
+
 type InMemBuffer[T any] struct {
+	mu     sync.RWMutex
 	buffer []T
 }
 
 func (b *InMemBuffer[T]) Size() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	return len(b.buffer)
 }
 
 func (b *InMemBuffer[T]) Empty() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	return len(b.buffer) == 0
 }
 
 func (b *InMemBuffer[T]) Items() []T {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.items()
+}
+
+func (b *InMemBuffer[T]) items() []T {
 	// Create a copy of the actual buffer
 	cpy := make([]T, len(b.buffer))
 	copy(cpy, b.buffer)
@@ -20,19 +33,23 @@ func (b *InMemBuffer[T]) Items() []T {
 }
 
 func (b *InMemBuffer[T]) Clear() []T {
-	cpy := b.Items()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cpy := b.items()
 	b.buffer = make([]T, 0)
 	return cpy
 }
 
 func (b *InMemBuffer[T]) Put(newItem T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	b.buffer = append(b.buffer, newItem)
 }
 
 func (b *InMemBuffer[T]) PutAll(newItems []T) {
-	for _, item := range newItems {
-		b.Put(item)
-	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buffer = append(b.buffer, newItems...)
 }
 
 func NewInMemBuffer[T any]() InMemBuffer[T] {
@@ -1,20 +1,36 @@
 package inmemorystorage
 
+import "sync"
+
 type InMemChannelWrapper[T any] struct {
+	mu      sync.Mutex
 	channel chan T
+	// Whether the channel of the current round has not been answered yet
+	pending bool
 }
 
 func (w *InMemChannelWrapper[T]) InitChannel() chan T {
-	w.channel = make(chan T)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.channel = make(chan T, 1)
+	w.pending = true
 	return w.channel
 }
 
-func (w *InMemChannelWrapper[T]) Get() chan T {
-	return w.channel
+func (w *InMemChannelWrapper[T]) Send(value T) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.pending {
+		return false
+	}
+	// The channel holds the one answer of its round, so sending never blocks
+	w.channel <- value
+	w.pending = false
+	return true
 }
 
 func NewInMemChannelWrapper[T any]() InMemChannelWrapper[T] {
 	return InMemChannelWrapper[T]{
-		channel: make(chan T),
+		channel: make(chan T, 1),
 	}
 }
@@ -0,0 +1,28 @@
+package inmemorystorage
+
+import "testing"
+
+func TestChannelWrapperAnswersRoundOnce(t *testing.T) {
+	wrapper := NewInMemChannelWrapper[string]()
+	if wrapper.Send("before") {
+		t.Fatal("Send succeeded without a round")
+	}
+
+	channel := wrapper.InitChannel()
+	if !wrapper.Send("aborted") {
+		t.Fatal("Send did not answer the round")
+	}
+	// A late completion of the aborted round must neither block nor replace the answer
+	if wrapper.Send("completed") {
+		t.Fatal("Send answered the round twice")
+	}
+	if answer := <-channel; answer != "aborted" {
+		t.Fatalf("round answered with %q, want %q", answer, "aborted")
+	}
+
+	// The next round gets its own channel
+	next := wrapper.InitChannel()
+	if !wrapper.Send("next") || <-next != "next" {
+		t.Fatal("next round was not answered")
+	}
+}
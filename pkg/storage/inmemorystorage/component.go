@@ -0,0 +1,92 @@
+package inmemorystorage
+
+import (
+	"context"
+	"go-kube/internal/broadcast"
+	"go-kube/pkg/misim"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ComponentInMemoryStorage struct {
+	mu sync.Mutex
+
+	// Components by User-Agent
+	components           map[string]*misim.Component
+	componentChan        chan misim.Component
+	componentBroadcaster *broadcast.BroadcastServer[misim.Component]
+}
+
+// ComponentStorage interface
+
+func (s *ComponentInMemoryStorage) Seen(userAgent string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.component(userAgent).LastSeen = time.Now()
+}
+
+func (s *ComponentInMemoryStorage) WatchStarted(userAgent string, resource string) func() {
+	s.changeWatches(userAgent, resource, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { s.changeWatches(userAgent, resource, -1) })
+	}
+}
+
+func (s *ComponentInMemoryStorage) GetComponents() ([]misim.Component, *broadcast.BroadcastServer[misim.Component]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	components := make([]misim.Component, 0, len(s.components))
+	for _, component := range s.components {
+		components = append(components, copyComponent(component))
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].UserAgent < components[j].UserAgent })
+	return components, s.componentBroadcaster
+}
+
+func (s *ComponentInMemoryStorage) changeWatches(userAgent string, resource string, delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	component := s.component(userAgent)
+	component.LastSeen = time.Now()
+	component.Watches[resource] += delta
+	if component.Watches[resource] <= 0 {
+		delete(component.Watches, resource)
+	}
+	// Watches still end after the cluster stopped, the stopped broadcaster discards the message then
+	s.componentChan <- copyComponent(component)
+}
+
+// Returns the component with the passed User-Agent, it is added if it is unknown
+func (s *ComponentInMemoryStorage) component(userAgent string) *misim.Component {
+	component, found := s.components[userAgent]
+	if !found {
+		// e.g. "kube-scheduler/v1.27.2 (linux/amd64) kubernetes/7f6f68f/scheduler"
+		name, _, _ := strings.Cut(userAgent, "/")
+		component = &misim.Component{Name: name, UserAgent: userAgent, Watches: make(map[string]int)}
+		s.components[userAgent] = component
+	}
+	return component
+}
+
+func copyComponent(component *misim.Component) misim.Component {
+	copied := *component
+	copied.Watches = make(map[string]int, len(component.Watches))
+	for resource, count := range component.Watches {
+		copied.Watches[resource] = count
+	}
+	return copied
+}
+
+// Constructors
+
+func NewComponentInMemoryStorage(ctx context.Context) ComponentInMemoryStorage {
+	componentChan := make(chan misim.Component, 500)
+	return ComponentInMemoryStorage{
+		components:           make(map[string]*misim.Component),
+		componentChan:        componentChan,
+		componentBroadcaster: broadcast.NewBroadcastServer(ctx, "ComponentBroadcaster", componentChan),
+	}
+}
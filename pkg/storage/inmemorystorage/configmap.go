@@ -0,0 +1,68 @@
+package inmemorystorage
+
+import (
+	"context"
+	"go-kube/internal/broadcast"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sync"
+)
+
+type ConfigMapInMemoryStorage struct {
+	mu sync.RWMutex
+
+	configMaps           core.ConfigMapList
+	configMapEventChan   chan metav1.WatchEvent
+	configMapBroadcaster *broadcast.BroadcastServer[metav1.WatchEvent]
+}
+
+// ConfigMapStorage interface
+
+func (s *ConfigMapInMemoryStorage) StoreConfigMaps(cms core.ConfigMapList, events []metav1.WatchEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configMaps = *cms.DeepCopy()
+	for _, event := range events {
+		s.configMapEventChan <- snapshotEvent(event)
+	}
+}
+
+func (s *ConfigMapInMemoryStorage) GetConfigMaps() (core.ConfigMapList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return *s.configMaps.DeepCopy(), s.configMapBroadcaster
+}
+
+func (s *ConfigMapInMemoryStorage) GetConfigMap(namespace string, name string) (core.ConfigMap, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, configMap := range s.configMaps.Items {
+		if configMap.Namespace == namespace && configMap.Name == name {
+			return *configMap.DeepCopy(), true
+		}
+	}
+	return core.ConfigMap{}, false
+}
+
+func (s *ConfigMapInMemoryStorage) Reset() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.configMaps.Items {
+		s.configMapEventChan <- snapshotEvent(metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &s.configMaps.Items[i]}})
+	}
+	deleted := len(s.configMaps.Items)
+	s.configMaps = core.ConfigMapList{TypeMeta: metav1.TypeMeta{Kind: "ConfigMapList", APIVersion: "v1"}, Items: nil}
+	return deleted
+}
+
+// Constructors
+
+func NewConfigMapInMemoryStorage(ctx context.Context) ConfigMapInMemoryStorage {
+	configMapEventChan := make(chan metav1.WatchEvent, 500)
+	return ConfigMapInMemoryStorage{
+		configMaps:           core.ConfigMapList{TypeMeta: metav1.TypeMeta{Kind: "ConfigMapList", APIVersion: "v1"}, Items: nil},
+		configMapEventChan:   configMapEventChan,
+		configMapBroadcaster: broadcast.NewBroadcastServer(ctx, "ConfigMapBroadcaster", configMapEventChan),
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"go-kube/internal/broadcast"
 	apps "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sync"
 )
 
 type DaemonSetInMemoryStorage struct {
+	mu sync.RWMutex
+
 	daemonSets           apps.DaemonSetList
 	daemonSetEventChan   chan metav1.WatchEvent
 	daemonSetBroadcaster *broadcast.BroadcastServer[metav1.WatchEvent]
@@ -15,24 +19,39 @@ type DaemonSetInMemoryStorage struct {
 
 // DaemonSetStorage interface
 
-func (d DaemonSetInMemoryStorage) StoreDaemonSets(ds apps.DaemonSetList, events []metav1.WatchEvent) {
-	d.daemonSets = ds
+func (d *DaemonSetInMemoryStorage) StoreDaemonSets(ds apps.DaemonSetList, events []metav1.WatchEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.daemonSets = *ds.DeepCopy()
 	for _, event := range events {
-		d.daemonSetEventChan <- event
+		d.daemonSetEventChan <- snapshotEvent(event)
 	}
 }
 
-func (d DaemonSetInMemoryStorage) GetDaemonSets() (apps.DaemonSetList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
-	return d.daemonSets, d.daemonSetBroadcaster
+func (d *DaemonSetInMemoryStorage) GetDaemonSets() (apps.DaemonSetList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return *d.daemonSets.DeepCopy(), d.daemonSetBroadcaster
+}
+
+func (d *DaemonSetInMemoryStorage) Reset() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range d.daemonSets.Items {
+		d.daemonSetEventChan <- snapshotEvent(metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &d.daemonSets.Items[i]}})
+	}
+	deleted := len(d.daemonSets.Items)
+	d.daemonSets = apps.DaemonSetList{TypeMeta: metav1.TypeMeta{Kind: "DaemonSetList", APIVersion: "apps/v1"}, Items: nil}
+	return deleted
 }
 
 // Constructors
 
-func NewDaemonSetInMemoryStorage() DaemonSetInMemoryStorage {
-	daemonSetEventChan := make(chan metav1.WatchEvent)
+func NewDaemonSetInMemoryStorage(ctx context.Context) DaemonSetInMemoryStorage {
+	daemonSetEventChan := make(chan metav1.WatchEvent, 500)
 	return DaemonSetInMemoryStorage{
 		daemonSets:           apps.DaemonSetList{TypeMeta: metav1.TypeMeta{Kind: "DaemonSetList", APIVersion: "apps/v1"}, Items: nil},
 		daemonSetEventChan:   daemonSetEventChan,
-		daemonSetBroadcaster: broadcast.NewBroadcastServer(context.TODO(), "DaemonSetBroadcaster", daemonSetEventChan),
+		daemonSetBroadcaster: broadcast.NewBroadcastServer(ctx, "DaemonSetBroadcaster", daemonSetEventChan),
 	}
 }
@@ -5,9 +5,12 @@ import (
 	eventsv1 "k8s.io/api/events/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"sync"
 )
 
 type EventInMemoryStorage struct {
+	mu sync.RWMutex
+
 	eventsApiEvents []eventsv1.Event
 	coreApiEvents   []v1.Event
 	//eventChan        chan metav1.WatchEvent
@@ -15,7 +18,9 @@ type EventInMemoryStorage struct {
 }
 
 func (e *EventInMemoryStorage) StoreEventsApiEvent(event eventsv1.Event) eventsv1.Event {
-	e.eventsApiEvents = append(e.eventsApiEvents, event)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.eventsApiEvents = append(e.eventsApiEvents, *event.DeepCopy())
 	klog.V(7).Infof("EventInMemoryStorage.StoreEvent: %v", event)
 	return event
 }
@@ -29,13 +34,20 @@ func (e *EventInMemoryStorage) GetEventsApiEvents() eventsv1.EventList {
 
 	eventList := eventsv1.EventList{}
 	eventList.TypeMeta = typeMeta
-	eventList.Items = e.eventsApiEvents
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	eventList.Items = make([]eventsv1.Event, len(e.eventsApiEvents))
+	for i := range e.eventsApiEvents {
+		e.eventsApiEvents[i].DeepCopyInto(&eventList.Items[i])
+	}
 	return eventList
 
 }
 
 func (e *EventInMemoryStorage) StoreCoreApiEvent(event v1.Event) v1.Event {
-	e.coreApiEvents = append(e.coreApiEvents, event)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.coreApiEvents = append(e.coreApiEvents, *event.DeepCopy())
 	klog.V(7).Infof("EventInMemoryStorage.StoreEvent: %v", event)
 	return event
 }
@@ -49,10 +61,24 @@ func (e *EventInMemoryStorage) GetCoreApiEvents() v1.EventList {
 
 	eventList := v1.EventList{}
 	eventList.TypeMeta = typeMeta
-	eventList.Items = e.coreApiEvents
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	eventList.Items = make([]v1.Event, len(e.coreApiEvents))
+	for i := range e.coreApiEvents {
+		e.coreApiEvents[i].DeepCopyInto(&eventList.Items[i])
+	}
 	return eventList
 }
 
+func (e *EventInMemoryStorage) Reset() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	deleted := len(e.eventsApiEvents) + len(e.coreApiEvents)
+	e.eventsApiEvents = []eventsv1.Event{}
+	e.coreApiEvents = []v1.Event{}
+	return deleted
+}
+
 func NewEventInMemoryStorage() EventInMemoryStorage {
 	//eventChan := make(chan metav1.WatchEvent)
 	return EventInMemoryStorage{
@@ -0,0 +1,32 @@
+package inmemorystorage
+
+import (
+	"go-kube/pkg/admin"
+	"go-kube/pkg/faults"
+	"sync"
+)
+
+type FaultInMemoryStorage struct {
+	mu       sync.RWMutex
+	injector *faults.Injector
+}
+
+// FaultStorage interface
+
+func (s *FaultInMemoryStorage) GetFaults() *faults.Injector {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.injector
+}
+
+func (s *FaultInMemoryStorage) StoreFaults(injector *faults.Injector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injector = injector
+}
+
+// Constructors
+
+func NewFaultInMemoryStorage() FaultInMemoryStorage {
+	return FaultInMemoryStorage{injector: faults.NewInjector(admin.FaultConfig{Rules: []admin.FaultRule{}})}
+}
@@ -0,0 +1,179 @@
+package inmemorystorage
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Pointer to a Kubernetes object, e.g. *v1.Pod
+type objectPointer[T any] interface {
+	*T
+	metav1.Object
+	DeepCopy() *T
+}
+
+// Returns the keys under which an object is found in a secondary index
+type indexFunc[T any] func(object *T) []string
+
+// Objects of one kind with O(1) lookups by name and secondary indexes.
+// Objects are deep-copied when they are stored and when they are returned, so
+// neither the caller nor a pending watch event can change the stored state.
+// It is not safe for concurrent use, the storages guard it with their own lock.
+type indexedObjects[T any, PT objectPointer[T]] struct {
+	items  []T
+	byName map[string]int
+	// Index name -> key -> names of the objects
+	indexes  map[string]map[string]map[string]struct{}
+	indexers map[string]indexFunc[T]
+}
+
+func newIndexedObjects[T any, PT objectPointer[T]](indexers map[string]indexFunc[T]) indexedObjects[T, PT] {
+	o := indexedObjects[T, PT]{
+		byName:   make(map[string]int),
+		indexes:  make(map[string]map[string]map[string]struct{}, len(indexers)),
+		indexers: indexers,
+	}
+	for name := range indexers {
+		o.indexes[name] = make(map[string]map[string]struct{})
+	}
+	return o
+}
+
+// Replaces all objects
+func (o *indexedObjects[T, PT]) replace(items []T) {
+	o.items = make([]T, len(items))
+	for i := range items {
+		o.items[i] = *PT(&items[i]).DeepCopy()
+	}
+	o.byName = make(map[string]int, len(items))
+	for name := range o.indexers {
+		o.indexes[name] = make(map[string]map[string]struct{})
+	}
+	for i := range o.items {
+		name := PT(&o.items[i]).GetName()
+		o.byName[name] = i
+		o.addToIndexes(name, &o.items[i])
+	}
+}
+
+// Returns copies of all objects
+func (o *indexedObjects[T, PT]) list() []T {
+	if o.items == nil {
+		return nil
+	}
+	items := make([]T, len(o.items))
+	for i := range o.items {
+		items[i] = *PT(&o.items[i]).DeepCopy()
+	}
+	return items
+}
+
+func (o *indexedObjects[T, PT]) get(name string) (T, bool) {
+	i, found := o.byName[name]
+	if !found {
+		var empty T
+		return empty, false
+	}
+	return *PT(&o.items[i]).DeepCopy(), true
+}
+
+// Replaces the object stored under the passed name, returns false if there is none.
+// The object may be renamed, but not to the name of another stored object.
+func (o *indexedObjects[T, PT]) update(name string, object T) bool {
+	i, found := o.byName[name]
+	if !found {
+		return false
+	}
+	newName := PT(&object).GetName()
+	if _, taken := o.byName[newName]; taken && newName != name {
+		return false
+	}
+	o.removeFromIndexes(name, &o.items[i])
+	o.items[i] = *PT(&object).DeepCopy()
+	if newName != name {
+		delete(o.byName, name)
+		o.byName[newName] = i
+	}
+	o.addToIndexes(newName, &o.items[i])
+	return true
+}
+
+// Adds the object, or replaces it if an object with the same name is stored
+func (o *indexedObjects[T, PT]) add(object T) {
+	name := PT(&object).GetName()
+	if o.update(name, object) {
+		return
+	}
+	o.items = append(o.items, *PT(&object).DeepCopy())
+	o.byName[name] = len(o.items) - 1
+	o.addToIndexes(name, &o.items[len(o.items)-1])
+}
+
+// Removes the object with the passed name. The last object takes its place,
+// so the order of the remaining objects changes.
+func (o *indexedObjects[T, PT]) remove(name string) (T, bool) {
+	i, found := o.byName[name]
+	if !found {
+		var empty T
+		return empty, false
+	}
+	removed := o.items[i]
+	o.removeFromIndexes(name, &removed)
+	last := len(o.items) - 1
+	if i != last {
+		o.items[i] = o.items[last]
+		o.byName[PT(&o.items[i]).GetName()] = i
+	}
+	var empty T
+	o.items[last] = empty
+	o.items = o.items[:last]
+	delete(o.byName, name)
+	return removed, true
+}
+
+// Removes all objects and returns them
+func (o *indexedObjects[T, PT]) removeAll() []T {
+	removed := o.items
+	o.replace(nil)
+	return removed
+}
+
+// Returns the objects found under the key of the passed index, in storage order
+func (o *indexedObjects[T, PT]) byIndex(index string, key string) []T {
+	names := o.indexes[index][key]
+	positions := make([]int, 0, len(names))
+	for name := range names {
+		positions = append(positions, o.byName[name])
+	}
+	sort.Ints(positions)
+	result := make([]T, len(positions))
+	for i, position := range positions {
+		result[i] = *PT(&o.items[position]).DeepCopy()
+	}
+	return result
+}
+
+func (o *indexedObjects[T, PT]) addToIndexes(name string, object *T) {
+	for index, indexer := range o.indexers {
+		for _, key := range indexer(object) {
+			names, found := o.indexes[index][key]
+			if !found {
+				names = make(map[string]struct{})
+				o.indexes[index][key] = names
+			}
+			names[name] = struct{}{}
+		}
+	}
+}
+
+func (o *indexedObjects[T, PT]) removeFromIndexes(name string, object *T) {
+	for index, indexer := range o.indexers {
+		for _, key := range indexer(object) {
+			delete(o.indexes[index][key], name)
+			if len(o.indexes[index][key]) == 0 {
+				delete(o.indexes[index], key)
+			}
+		}
+	}
+}
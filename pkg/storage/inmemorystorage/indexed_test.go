@@ -0,0 +1,294 @@
+package inmemorystorage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Sizes of the largest clusters the adapter is used with
+const (
+	benchmarkNodes = 10000
+	benchmarkPods  = 100000
+)
+
+func testNodes(count int) []core.Node {
+	nodes := make([]core.Node, count)
+	for i := range nodes {
+		nodes[i] = core.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}}
+	}
+	return nodes
+}
+
+// Pods spread evenly over the nodes, every tenth pod is pending
+func testPods(count int, nodes int) []core.Pod {
+	pods := make([]core.Pod, count)
+	for i := range pods {
+		pods[i] = core.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d", i), Namespace: "default"}}
+		if i%10 != 0 {
+			pods[i].Spec.NodeName = fmt.Sprintf("node-%d", i%nodes)
+		}
+	}
+	return pods
+}
+
+func TestIndexedObjects(t *testing.T) {
+	storage := NewPodInMemoryStorage(context.Background())
+	pods := &storage.pods
+	pods.replace(testPods(20, 2))
+
+	if pod, found := pods.get("pod-3"); !found || pod.Spec.NodeName != "node-1" {
+		t.Fatalf("get(pod-3) = %v, %v", pod.Spec.NodeName, found)
+	}
+	if onNode := pods.byIndex(podsByNodeIndex, "node-1"); len(onNode) != 10 {
+		t.Fatalf("byIndex(node-1) returned %d pods, want 10", len(onNode))
+	}
+
+	// Moving a pod updates the index
+	moved, _ := pods.get("pod-3")
+	moved.Spec.NodeName = "node-0"
+	if !pods.update("pod-3", moved) {
+		t.Fatal("update(pod-3) did not find the pod")
+	}
+	if onNode := pods.byIndex(podsByNodeIndex, "node-1"); len(onNode) != 9 {
+		t.Fatalf("byIndex(node-1) returned %d pods after the move, want 9", len(onNode))
+	}
+
+	// Renaming onto the name of another pod would make that pod unreachable
+	renamed, _ := pods.get("pod-3")
+	renamed.Name = "pod-4"
+	if pods.update("pod-3", renamed) {
+		t.Fatal("update(pod-3) renamed the pod onto pod-4")
+	}
+	if pod, _ := pods.get("pod-4"); pod.Spec.NodeName != "node-0" {
+		t.Fatalf("pod-4 is on %q after the rejected rename, want node-0", pod.Spec.NodeName)
+	}
+	renamed.Name = "pod-3-renamed"
+	if !pods.update("pod-3", renamed) {
+		t.Fatal("update(pod-3) did not rename the pod")
+	}
+	if _, found := pods.get("pod-3"); found {
+		t.Fatal("pod-3 is still found after renaming it")
+	}
+	if _, found := pods.get("pod-3-renamed"); !found {
+		t.Fatal("renamed pod is not found by its new name")
+	}
+
+	// The last pod takes the place of the removed one and stays reachable by name
+	if _, found := pods.remove("pod-5"); !found {
+		t.Fatal("remove(pod-5) did not find the pod")
+	}
+	if _, found := pods.get("pod-5"); found {
+		t.Fatal("pod-5 is still found after removing it")
+	}
+	if pod, found := pods.get("pod-19"); !found || pod.Name != "pod-19" {
+		t.Fatalf("get(pod-19) = %q, %v after removing pod-5", pod.Name, found)
+	}
+	if len(pods.list()) != 19 {
+		t.Fatalf("list() returned %d pods, want 19", len(pods.list()))
+	}
+
+	// Returned objects are copies
+	copied, _ := pods.get("pod-1")
+	copied.Labels = map[string]string{"changed": "true"}
+	if stored, _ := pods.get("pod-1"); stored.Labels != nil {
+		t.Fatal("changing a returned pod changed the stored one")
+	}
+}
+
+// Run with -race, readers and writers use the storage at the same time
+func TestPodStorageConcurrentAccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	storage := NewPodInMemoryStorage(ctx)
+	storage.StorePods(core.PodList{Items: testPods(1000, 10)}, nil)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				name := fmt.Sprintf("pod-%d", (worker*200+i)%1000)
+				switch i % 5 {
+				case 0:
+					storage.GetPod(name)
+				case 1:
+					storage.GetPodsOnNode(fmt.Sprintf("node-%d", i%10))
+				case 2:
+					pod := storage.GetPod(name)
+					if pod.Name != "" {
+						pod.Spec.NodeName = "node-0"
+						storage.UpdatePod(name, pod)
+					}
+				case 3:
+					storage.DeletePod(name)
+				case 4:
+					pods, _ := storage.GetPods()
+					if i%50 == 4 {
+						storage.StorePods(pods, nil)
+					}
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	pods, _ := storage.GetPods()
+	for _, pod := range pods.Items {
+		if storage.GetPod(pod.Name).Name != pod.Name {
+			t.Fatalf("pod %s is listed but not found by name", pod.Name)
+		}
+	}
+}
+
+// Run with -race, readers and writers use the storage at the same time
+func TestNodeStorageConcurrentAccess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	storage := NewNodeInMemoryStorage(ctx)
+	storage.StoreNodes(core.NodeList{Items: testNodes(100)}, nil)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				name := fmt.Sprintf("node-%d", (worker*100+i)%100)
+				switch i % 4 {
+				case 0:
+					storage.GetNode(name)
+				case 1:
+					node := storage.GetNode(name)
+					if node.Name != "" {
+						node.Labels = map[string]string{"worker": fmt.Sprint(worker)}
+						storage.PutNode(name, node)
+					}
+				case 2:
+					storage.DeleteNode(name)
+				case 3:
+					nodes, _ := storage.GetNodes()
+					if i%20 == 3 {
+						event := metav1.WatchEvent{Type: "MODIFIED", Object: runtime.RawExtension{Object: &core.Node{}}}
+						storage.StoreNodes(nodes, []metav1.WatchEvent{event})
+					}
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	nodes, _ := storage.GetNodes()
+	for _, node := range nodes.Items {
+		if storage.GetNode(node.Name).Name != node.Name {
+			t.Fatalf("node %s is listed but not found by name", node.Name)
+		}
+	}
+}
+
+// Readers must not wait for writers that are blocked by a slow broadcaster
+func TestReadersDoNotWaitForEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	storage := NewPodInMemoryStorage(ctx)
+	storage.StorePods(core.PodList{Items: testPods(10, 1)}, nil)
+	// The subscriber never reads, so the broadcaster and then the event channel run full
+	storage.podBroadcaster.Subscribe()
+
+	go func() {
+		for i := 0; ctx.Err() == nil; i++ {
+			pod := storage.GetPod(fmt.Sprintf("pod-%d", i%10))
+			storage.UpdatePod(pod.Name, pod)
+		}
+	}()
+	for len(storage.podEventChan) < cap(storage.podEventChan) {
+		time.Sleep(time.Millisecond)
+	}
+	// Gives the writer time to block on the next event
+	time.Sleep(50 * time.Millisecond)
+
+	read := make(chan struct{})
+	go func() {
+		storage.GetPods()
+		storage.GetPod("pod-1")
+		storage.GetPodsOnNode("node-0")
+		close(read)
+	}()
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("readers are blocked while the event channel is full")
+	}
+}
+
+func BenchmarkGetPod(b *testing.B) {
+	storage := NewPodInMemoryStorage(context.Background())
+	storage.StorePods(core.PodList{Items: testPods(benchmarkPods, benchmarkNodes)}, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		storage.GetPod(fmt.Sprintf("pod-%d", i%benchmarkPods))
+	}
+}
+
+func BenchmarkGetPodsOnNode(b *testing.B) {
+	storage := NewPodInMemoryStorage(context.Background())
+	storage.StorePods(core.PodList{Items: testPods(benchmarkPods, benchmarkNodes)}, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		storage.GetPodsOnNode(fmt.Sprintf("node-%d", i%benchmarkNodes))
+	}
+}
+
+func BenchmarkUpdatePod(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	storage := NewPodInMemoryStorage(ctx)
+	pods := testPods(benchmarkPods, benchmarkNodes)
+	storage.StorePods(core.PodList{Items: pods}, nil)
+	// Drains the MODIFIED events like a watcher that keeps up
+	subscription := storage.podBroadcaster.Subscribe()
+	go func() {
+		for range subscription {
+		}
+	}()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pod := pods[i%benchmarkPods]
+		pod.Spec.NodeName = fmt.Sprintf("node-%d", (i+1)%benchmarkNodes)
+		storage.UpdatePod(pod.Name, pod)
+	}
+}
+
+func BenchmarkStorePods(b *testing.B) {
+	storage := NewPodInMemoryStorage(context.Background())
+	pods := core.PodList{Items: testPods(benchmarkPods, benchmarkNodes)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		storage.StorePods(pods, nil)
+	}
+}
+
+func BenchmarkGetNode(b *testing.B) {
+	storage := NewNodeInMemoryStorage(context.Background())
+	storage.StoreNodes(core.NodeList{Items: testNodes(benchmarkNodes)}, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		storage.GetNode(fmt.Sprintf("node-%d", i%benchmarkNodes))
+	}
+}
+
+func BenchmarkStoreNodes(b *testing.B) {
+	storage := NewNodeInMemoryStorage(context.Background())
+	nodes := core.NodeList{Items: testNodes(benchmarkNodes)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		storage.StoreNodes(nodes, nil)
+	}
+}
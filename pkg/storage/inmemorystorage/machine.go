@@ -7,91 +7,171 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	cluster "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sync"
+)
+
+const (
+	machinesByMachineSetIndex = "machineSet"
+	machinesByNodeIndex       = "node"
 )
 
 type MachineInMemoryStorage struct {
-	machines           cluster.MachineList
+	mu sync.RWMutex
+	// Held by writers until their events are sent, see sendEvents
+	sendMu sync.Mutex
+
+	// Type and list metadata of the stored list, its items are kept in machines
+	machineList        cluster.MachineList
+	machines           indexedObjects[cluster.Machine, *cluster.Machine]
 	machineEventChan   chan metav1.WatchEvent
 	machineBroadcaster *broadcast.BroadcastServer[metav1.WatchEvent]
 	machineCount       int
 }
 
 func (s *MachineInMemoryStorage) GetMachines() (cluster.MachineList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
-	return s.machines, s.machineBroadcaster
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	machines := s.machineList
+	machines.Items = s.machines.list()
+	return machines, s.machineBroadcaster
 }
 
 func (s *MachineInMemoryStorage) StoreMachines(ms cluster.MachineList, events []metav1.WatchEvent) {
-	s.machines = ms
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	s.machines.replace(ms.Items)
+	ms.Items = nil
+	s.machineList = ms
 	for _, n := range events {
-		s.machineEventChan <- n
 		if n.Type == "ADDED" {
-			s.IncrementMachineCount()
+			s.incrementMachineCount()
 		}
 	}
+	snapshots := snapshotEvents(events)
+	s.mu.Unlock()
+	sendEvents(s.machineEventChan, snapshots)
 }
 
 func (s *MachineInMemoryStorage) GetMachine(machineName string) cluster.Machine {
-	var machineRef cluster.Machine
-	for _, ms := range s.machines.Items {
-		if ms.Name == machineName {
-			machineRef = ms
-			break
-		}
-	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	machineRef, _ := s.machines.get(machineName)
 	return machineRef
 }
 
-func (s *MachineInMemoryStorage) PutMachine(machineName string, u cluster.Machine) cluster.Machine {
-	indexForReplacement := -1
-	for index, machine := range s.machines.Items {
-		if machine.Name == machineName {
-			indexForReplacement = index
-			break
-		}
+func (s *MachineInMemoryStorage) GetMachinesOfMachineSet(machineSetName string) []cluster.Machine {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.machines.byIndex(machinesByMachineSetIndex, machineSetName)
+}
+
+func (s *MachineInMemoryStorage) GetMachineOfNode(nodeName string) (cluster.Machine, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	machines := s.machines.byIndex(machinesByNodeIndex, nodeName)
+	if len(machines) == 0 {
+		return cluster.Machine{}, false
 	}
-	s.machines.Items[indexForReplacement] = u
+	return machines[0], true
+}
+
+func (s *MachineInMemoryStorage) PutMachine(machineName string, u cluster.Machine) cluster.Machine {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.machines.update(machineName, u)
 	return u
 }
 
 func (s *MachineInMemoryStorage) AddMachine(machine cluster.Machine) {
-	s.machines.Items = append(s.machines.Items, machine)
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	s.machines.add(machine)
+	s.mu.Unlock()
 	// Fire watch event
 	machineAddEvent := metav1.WatchEvent{Type: "ADDED", Object: runtime.RawExtension{Object: &machine}}
-	s.machineEventChan <- machineAddEvent
+	s.machineEventChan <- snapshotEvent(machineAddEvent)
 }
 
 func (s *MachineInMemoryStorage) DeleteMachine(machineName string) cluster.Machine {
-	var index int
-	var deletedMachine cluster.Machine
-	for i, machine := range s.machines.Items {
-		if machine.Name == machineName {
-			index = i
-			deletedMachine = machine
-			break
-		}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	deletedMachine, found := s.machines.remove(machineName)
+	s.mu.Unlock()
+	if found {
+		// Fire deleted event
+		s.machineEventChan <- snapshotEvent(metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &deletedMachine}})
 	}
-	s.machines.Items[index] = s.machines.Items[len(s.machines.Items)-1]
-	s.machines.Items = s.machines.Items[:len(s.machines.Items)-1]
-	// Fire deleted event
-	s.machineEventChan <- metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &s.machines.Items[index]}}
 	return deletedMachine
 }
 
 func (s *MachineInMemoryStorage) IncrementMachineCount() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.incrementMachineCount()
+}
+
+func (s *MachineInMemoryStorage) incrementMachineCount() {
 	s.machineCount = s.machineCount + 1
 	klog.V(4).Infof("Incremented machine count to %d", s.machineCount)
 }
 
 func (s *MachineInMemoryStorage) GetMachineCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.machineCount
 }
 
-func NewMachineInMemoryStorage() MachineInMemoryStorage {
+func (s *MachineInMemoryStorage) Reset() int {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	deletedMachines := s.machines.removeAll()
+	s.machineList = cluster.MachineList{TypeMeta: metav1.TypeMeta{Kind: "MachineList", APIVersion: "cluster.x-k8s.io/v1beta1"}, Items: nil}
+	s.machineCount = 0
+	s.mu.Unlock()
+	// The removed machines are no longer stored, so they can be sent without copying them
+	for i := range deletedMachines {
+		s.machineEventChan <- metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &deletedMachines[i]}}
+	}
+	return len(deletedMachines)
+}
+
+// Returns the name of the machine set owning the machine
+func machineSetOfMachine(machine *cluster.Machine) []string {
+	for _, owner := range machine.OwnerReferences {
+		if owner.Kind == "MachineSet" {
+			return []string{owner.Name}
+		}
+	}
+	if name, found := machine.Labels[cluster.MachineSetNameLabel]; found {
+		return []string{name}
+	}
+	if name, found := machine.Annotations["machine-set-name"]; found {
+		return []string{name}
+	}
+	return nil
+}
+
+func nodeOfMachine(machine *cluster.Machine) []string {
+	if machine.Status.NodeRef == nil {
+		return nil
+	}
+	return []string{machine.Status.NodeRef.Name}
+}
+
+func NewMachineInMemoryStorage(ctx context.Context) MachineInMemoryStorage {
 	machineEventChan := make(chan metav1.WatchEvent, 500)
 	return MachineInMemoryStorage{
-		machines:           cluster.MachineList{TypeMeta: metav1.TypeMeta{Kind: "MachineList", APIVersion: "cluster.x-k8s.io/v1beta1"}, Items: nil},
+		machineList: cluster.MachineList{TypeMeta: metav1.TypeMeta{Kind: "MachineList", APIVersion: "cluster.x-k8s.io/v1beta1"}, Items: nil},
+		machines: newIndexedObjects[cluster.Machine](map[string]indexFunc[cluster.Machine]{
+			machinesByMachineSetIndex: machineSetOfMachine,
+			machinesByNodeIndex:       nodeOfMachine,
+		}),
 		machineEventChan:   machineEventChan,
-		machineBroadcaster: broadcast.NewBroadcastServer(context.TODO(), "MachineBroadcaster", machineEventChan),
+		machineBroadcaster: broadcast.NewBroadcastServer(ctx, "MachineBroadcaster", machineEventChan),
 		machineCount:       0,
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"go-kube/internal/broadcast"
 	"strconv"
+	"sync"
 
 	v1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -12,7 +13,11 @@ import (
 )
 
 type MachineSetsInMemoryStorage struct {
-	machineSets           cluster.MachineSetList
+	mu sync.RWMutex
+
+	// Type and list metadata of the stored list, its items are kept in machineSets
+	machineSetList        cluster.MachineSetList
+	machineSets           indexedObjects[cluster.MachineSet, *cluster.MachineSet]
 	machineSetsEventChan  chan metav1.WatchEvent
 	nodeStorage           *NodeInMemoryStorage
 	machineStorage        *MachineInMemoryStorage
@@ -20,49 +25,46 @@ type MachineSetsInMemoryStorage struct {
 }
 
 func (s *MachineSetsInMemoryStorage) GetMachineSets() (cluster.MachineSetList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
-	return s.machineSets, s.machineSetBroadcaster
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	machineSets := s.machineSetList
+	machineSets.Items = s.machineSets.list()
+	return machineSets, s.machineSetBroadcaster
 }
 
 func (s *MachineSetsInMemoryStorage) StoreMachineSets(ms cluster.MachineSetList, events []metav1.WatchEvent) {
-	s.machineSets = ms
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.machineSets.replace(ms.Items)
+	ms.Items = nil
+	s.machineSetList = ms
 	for _, e := range events {
-		s.machineSetsEventChan <- e
+		s.machineSetsEventChan <- snapshotEvent(e)
 	}
 }
 
 func (s *MachineSetsInMemoryStorage) GetMachineSet(machineSetName string) cluster.MachineSet {
-	var machineSet cluster.MachineSet
-	for _, set := range s.machineSets.Items {
-		if set.Name == machineSetName {
-			machineSet = set
-			break
-		}
-	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	machineSet, _ := s.machineSets.get(machineSetName)
 	return machineSet
 }
 
 func (s *MachineSetsInMemoryStorage) PutMachineSet(machineSetName string, machineSet cluster.MachineSet) cluster.MachineSet {
-	index := -1
-	for i, set := range s.machineSets.Items {
-		if set.Name == machineSetName {
-			index = i
-			break
-		}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.machineSets.update(machineSetName, machineSet) {
+		return machineSet
 	}
-	s.machineSets.Items[index] = machineSet
 	// Fire MODIFIED event
-	s.machineSetsEventChan <- metav1.WatchEvent{Type: "MODIFIED", Object: runtime.RawExtension{Object: &machineSet}}
+	s.machineSetsEventChan <- snapshotEvent(metav1.WatchEvent{Type: "MODIFIED", Object: runtime.RawExtension{Object: &machineSet}})
 	return machineSet
 }
 
 func (s *MachineSetsInMemoryStorage) GetMachineSetsScale(machineSetName string) v1.Scale {
-	var machineSetRef cluster.MachineSet
-	for _, ms := range s.machineSets.Items {
-		if ms.Name == machineSetName {
-			machineSetRef = ms
-			break
-		}
-	}
+	s.mu.RLock()
+	machineSetRef, _ := s.machineSets.get(machineSetName)
+	s.mu.RUnlock()
 
 	result := v1.Scale{TypeMeta: metav1.TypeMeta{APIVersion: "autoscaling/v1", Kind: "Scale"},
 		ObjectMeta: metav1.ObjectMeta{Name: machineSetName},
@@ -73,7 +75,9 @@ func (s *MachineSetsInMemoryStorage) GetMachineSetsScale(machineSetName string)
 }
 
 func (s *MachineSetsInMemoryStorage) IsUpscalingPossible() bool {
-	for _, ms := range s.machineSets.Items {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, ms := range s.machineSets.items {
 		maxSize, _ := strconv.Atoi(ms.Annotations["cluster.x-k8s.io/cluster-api-autoscaler-node-group-max-size"])
 		if *ms.Spec.Replicas < int32(maxSize) {
 			return true
@@ -83,7 +87,9 @@ func (s *MachineSetsInMemoryStorage) IsUpscalingPossible() bool {
 }
 
 func (s *MachineSetsInMemoryStorage) IsDownscalingPossible() bool {
-	for _, ms := range s.machineSets.Items {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, ms := range s.machineSets.items {
 		minSize, _ := strconv.Atoi(ms.Annotations["cluster.x-k8s.io/cluster-api-autoscaler-node-group-min-size"])
 		if *ms.Spec.Replicas > int32(minSize) {
 			return true
@@ -92,13 +98,25 @@ func (s *MachineSetsInMemoryStorage) IsDownscalingPossible() bool {
 	return false
 }
 
-func NewMachineSetInMemoryStorage(nodeStorage *NodeInMemoryStorage, machineStorage *MachineInMemoryStorage) MachineSetsInMemoryStorage {
+func (s *MachineSetsInMemoryStorage) Reset() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deletedMachineSets := s.machineSets.removeAll()
+	for i := range deletedMachineSets {
+		s.machineSetsEventChan <- snapshotEvent(metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &deletedMachineSets[i]}})
+	}
+	s.machineSetList = cluster.MachineSetList{TypeMeta: metav1.TypeMeta{Kind: "MachineSetList", APIVersion: "cluster-x.k8s.io/v1beta1"}, Items: nil}
+	return len(deletedMachineSets)
+}
+
+func NewMachineSetInMemoryStorage(ctx context.Context, nodeStorage *NodeInMemoryStorage, machineStorage *MachineInMemoryStorage) MachineSetsInMemoryStorage {
 	machineSetsEventChan := make(chan metav1.WatchEvent, 500)
 	return MachineSetsInMemoryStorage{
-		machineSets:           cluster.MachineSetList{TypeMeta: metav1.TypeMeta{Kind: "MachineSetList", APIVersion: "cluster-x.k8s.io/v1beta1"}, Items: nil},
+		machineSetList:        cluster.MachineSetList{TypeMeta: metav1.TypeMeta{Kind: "MachineSetList", APIVersion: "cluster-x.k8s.io/v1beta1"}, Items: nil},
+		machineSets:           newIndexedObjects[cluster.MachineSet](nil),
 		machineSetsEventChan:  machineSetsEventChan,
 		nodeStorage:           nodeStorage,
 		machineStorage:        machineStorage,
-		machineSetBroadcaster: broadcast.NewBroadcastServer(context.TODO(), "MachineSetBroadcaster", machineSetsEventChan),
+		machineSetBroadcaster: broadcast.NewBroadcastServer(ctx, "MachineSetBroadcaster", machineSetsEventChan),
 	}
 }
@@ -5,41 +5,83 @@ import (
 	"go-kube/internal/broadcast"
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sync"
 )
 
 type NamespaceInMemoryStorage struct {
-	namespaces           core.NamespaceList
+	mu sync.RWMutex
+
+	namespaces core.NamespaceList
+	// Names of the namespaces of a new cluster, they are restored by Reset
+	initialNamespaces    []string
 	namespaceEventChan   chan metav1.WatchEvent
 	namespaceBroadcaster *broadcast.BroadcastServer[metav1.WatchEvent]
 }
 
 func (s *NamespaceInMemoryStorage) GetNamespaces() (core.NamespaceList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
-	return s.namespaces, s.namespaceBroadcaster
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return *s.namespaces.DeepCopy(), s.namespaceBroadcaster
 }
 
-func (s *NamespaceInMemoryStorage) StoreNamespaces(namespaces core.NamespaceList) {
-	s.namespaces = namespaces
+func (s *NamespaceInMemoryStorage) StoreNamespaces(namespaces core.NamespaceList, events []metav1.WatchEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespaces = *namespaces.DeepCopy()
+	for _, event := range events {
+		s.namespaceEventChan <- snapshotEvent(event)
+	}
 }
 
 func (s *NamespaceInMemoryStorage) GetNamespace(namespaceName string) core.Namespace {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var u core.Namespace
 	for _, element := range s.namespaces.Items {
 		if namespaceName == element.Name {
-			u = element
+			u = *element.DeepCopy()
 			break
 		}
 	}
 	return u
 }
 
-func NewNamespaceInMemoryStorage() NamespaceInMemoryStorage {
-	var namespace core.Namespace
-	namespace.SetName("default")
-	namespace.Status = core.NamespaceStatus{Phase: "Active"}
-	namespaceEventChan := make(chan metav1.WatchEvent)
+func (s *NamespaceInMemoryStorage) Reset() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	initial := make(map[string]bool, len(s.initialNamespaces))
+	for _, name := range s.initialNamespaces {
+		initial[name] = true
+	}
+	deleted := 0
+	for i := range s.namespaces.Items {
+		if initial[s.namespaces.Items[i].Name] {
+			continue
+		}
+		s.namespaceEventChan <- snapshotEvent(metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &s.namespaces.Items[i]}})
+		deleted++
+	}
+	s.namespaces = newNamespaceList(s.initialNamespaces)
+	return deleted
+}
+
+// Returns a list of active namespaces with the passed names
+func newNamespaceList(names []string) core.NamespaceList {
+	namespaces := core.NamespaceList{TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"}, Items: make([]core.Namespace, len(names))}
+	for i, name := range names {
+		namespaces.Items[i].SetName(name)
+		namespaces.Items[i].Status = core.NamespaceStatus{Phase: "Active"}
+	}
+	return namespaces
+}
+
+func NewNamespaceInMemoryStorage(ctx context.Context, initialNamespaces []string) NamespaceInMemoryStorage {
+	namespaceEventChan := make(chan metav1.WatchEvent, 500)
 	return NamespaceInMemoryStorage{
-		namespaces:           core.NamespaceList{TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"}, Items: []core.Namespace{namespace}},
+		namespaces:           newNamespaceList(initialNamespaces),
+		initialNamespaces:    initialNamespaces,
 		namespaceEventChan:   namespaceEventChan,
-		namespaceBroadcaster: broadcast.NewBroadcastServer(context.TODO(), "NamespaceBroadcaster", namespaceEventChan),
+		namespaceBroadcaster: broadcast.NewBroadcastServer(ctx, "NamespaceBroadcaster", namespaceEventChan),
 	}
 }
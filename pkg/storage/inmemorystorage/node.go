@@ -7,10 +7,20 @@ import (
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sync"
 )
 
 type NodeInMemoryStorage struct {
-	nodes                      core.NodeList
+	// Held by the controllers across several calls, see BeginTransaction
+	transactionMu sync.Mutex
+	// Guards the nodes, held only within a single call
+	mu sync.RWMutex
+	// Held by writers until their events are sent, see sendEvents
+	sendMu sync.Mutex
+
+	// Type and list metadata of the stored list, its items are kept in nodes
+	nodeList                   core.NodeList
+	nodes                      indexedObjects[core.Node, *core.Node]
 	nodeEventChan              chan metav1.WatchEvent
 	nodeBroadcaster            *broadcast.BroadcastServer[metav1.WatchEvent]
 	nodeUpscalingChan          chan core.Node
@@ -21,70 +31,95 @@ type NodeInMemoryStorage struct {
 	deletedNodes               InMemBuffer[core.Node]
 }
 
+func (s *NodeInMemoryStorage) BeginTransaction() {
+	s.transactionMu.Lock()
+}
+
+func (s *NodeInMemoryStorage) EndTransaction() {
+	s.transactionMu.Unlock()
+}
+
 func (s *NodeInMemoryStorage) GetNodes() (core.NodeList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
-	return s.nodes, s.nodeBroadcaster
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	nodes := s.nodeList
+	nodes.Items = s.nodes.list()
+	return nodes, s.nodeBroadcaster
 }
 
 func (s *NodeInMemoryStorage) StoreNodes(nodes core.NodeList, events []metav1.WatchEvent) {
-	s.nodes = nodes
-	for _, n := range events {
-		s.nodeEventChan <- n
-	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	s.nodes.replace(nodes.Items)
+	nodes.Items = nil
+	s.nodeList = nodes
+	snapshots := snapshotEvents(events)
+	s.mu.Unlock()
+	sendEvents(s.nodeEventChan, snapshots)
 }
 
 func (s *NodeInMemoryStorage) PutNode(nodeName string, node core.Node) core.Node {
-	indexForReplacement := -1
-	for index, node := range s.nodes.Items {
-		if node.Name == nodeName {
-			indexForReplacement = index
-			break
-		}
-	}
-	s.nodes.Items[indexForReplacement] = node
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes.update(nodeName, node)
 	return node
 }
 
 func (s *NodeInMemoryStorage) GetNode(name string) core.Node {
-	var u core.Node
-
-	for _, node := range s.nodes.Items {
-		if node.Name == name {
-			u = node
-			break
-		}
-	}
-
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, _ := s.nodes.get(name)
 	return u
 }
 
 func (s *NodeInMemoryStorage) AddNode(node core.Node) {
-	s.nodes.Items = append(s.nodes.Items, node)
+	s.sendMu.Lock()
+	s.mu.Lock()
+	s.nodes.add(node)
+	s.mu.Unlock()
 	// Fire added event
 	nodeAddEvent := metav1.WatchEvent{Type: "ADDED", Object: runtime.RawExtension{Object: &node}}
-	s.nodeEventChan <- nodeAddEvent
-	s.nodeUpscalingChan <- node
+	s.nodeEventChan <- snapshotEvent(nodeAddEvent)
+	s.sendMu.Unlock()
+	// The scaling channels are unbuffered, so we do not block readers or writers while sending
+	s.nodeUpscalingChan <- *node.DeepCopy()
 }
 
 func (s *NodeInMemoryStorage) DeleteNode(nodeName string) core.Node {
-	// Get index
-	var index int
-	var deletedNode core.Node
-	for i, node := range s.nodes.Items {
-		if node.Name == nodeName {
-			index = i
-			deletedNode = node
-			break
-		}
+	s.sendMu.Lock()
+	s.mu.Lock()
+	deletedNode, found := s.nodes.remove(nodeName)
+	s.mu.Unlock()
+	if !found {
+		s.sendMu.Unlock()
+		return deletedNode
 	}
-	s.nodes.Items[index] = s.nodes.Items[len(s.nodes.Items)-1]
-	s.nodes.Items = s.nodes.Items[:len(s.nodes.Items)-1]
 	// Fire event
 	nodeDeleteEvent := metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &deletedNode}}
-	s.nodeEventChan <- nodeDeleteEvent
-	s.nodeDownscalingChan <- deletedNode
+	s.nodeEventChan <- snapshotEvent(nodeDeleteEvent)
+	s.sendMu.Unlock()
+	s.nodeDownscalingChan <- *deletedNode.DeepCopy()
 	return deletedNode
 }
 
+// Unlike DeleteNode, Reset does not announce the nodes as downscaled
+func (s *NodeInMemoryStorage) Reset() int {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	deletedNodes := s.nodes.removeAll()
+	s.nodeList = core.NodeList{TypeMeta: metav1.TypeMeta{Kind: "NodeList", APIVersion: "v1"}, Items: nil}
+	s.newNodes.Clear()
+	s.deletedNodes.Clear()
+	s.mu.Unlock()
+	// The removed nodes are no longer stored, so they can be sent without copying them
+	for i := range deletedNodes {
+		s.nodeEventChan <- metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &deletedNodes[i]}}
+	}
+	return len(deletedNodes)
+}
+
 func (s *NodeInMemoryStorage) GetNodeUpscalingChannel() *broadcast.BroadcastServer[core.Node] {
 	return s.nodeUpscalingBroadcaster
 }
@@ -101,18 +136,19 @@ func (s *NodeInMemoryStorage) DeletedNodes() storage.Buffer[core.Node] {
 	return &s.deletedNodes
 }
 
-func NewNodeInMemoryStorage() NodeInMemoryStorage {
+func NewNodeInMemoryStorage(ctx context.Context) NodeInMemoryStorage {
 	nodeEventChan := make(chan metav1.WatchEvent, 500)
 	nodeUpscalingChan := make(chan core.Node)
 	nodeDownscalingChan := make(chan core.Node)
 	return NodeInMemoryStorage{
-		nodes:                      core.NodeList{TypeMeta: metav1.TypeMeta{Kind: "NodeList", APIVersion: "v1"}, Items: nil},
+		nodeList:                   core.NodeList{TypeMeta: metav1.TypeMeta{Kind: "NodeList", APIVersion: "v1"}, Items: nil},
+		nodes:                      newIndexedObjects[core.Node](nil),
 		nodeEventChan:              nodeEventChan,
-		nodeBroadcaster:            broadcast.NewBroadcastServer(context.TODO(), "NodeBroadcaster", nodeEventChan),
+		nodeBroadcaster:            broadcast.NewBroadcastServer(ctx, "NodeBroadcaster", nodeEventChan),
 		nodeUpscalingChan:          nodeUpscalingChan,
 		nodeDownscalingChan:        nodeDownscalingChan,
-		nodeDownscalingBroadcaster: broadcast.NewBroadcastServer(context.TODO(), "NodeDownscalingBroadcaster", nodeDownscalingChan),
-		nodeUpscalingBroadcaster:   broadcast.NewBroadcastServer(context.TODO(), "NodeUpscalingBroadcaster", nodeUpscalingChan),
+		nodeDownscalingBroadcaster: broadcast.NewBroadcastServer(ctx, "NodeDownscalingBroadcaster", nodeDownscalingChan),
+		nodeUpscalingBroadcaster:   broadcast.NewBroadcastServer(ctx, "NodeUpscalingBroadcaster", nodeUpscalingChan),
 
 		newNodes:     NewInMemBuffer[core.Node](),
 		deletedNodes: NewInMemBuffer[core.Node](),
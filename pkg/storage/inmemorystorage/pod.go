@@ -12,10 +12,19 @@ import (
 	"sync"
 )
 
-type PodInMemoryStorage struct {
-	mu sync.Mutex
+const podsByNodeIndex = "node"
 
-	pods           core.PodList
+type PodInMemoryStorage struct {
+	// Held by the controllers across several calls, see BeginTransaction
+	transactionMu sync.Mutex
+	// Guards the pods, held only within a single call
+	mu sync.RWMutex
+	// Held by writers until their events are sent, see sendEvents
+	sendMu sync.Mutex
+
+	// Type and list metadata of the stored list, its items are kept in pods
+	podList        core.PodList
+	pods           indexedObjects[core.Pod, *core.Pod]
 	podEventChan   chan metav1.WatchEvent
 	podBroadcaster *broadcast.BroadcastServer[metav1.WatchEvent]
 	nextResourceId int
@@ -27,64 +36,113 @@ type PodInMemoryStorage struct {
 }
 
 func (s *PodInMemoryStorage) BeginTransaction() {
-	s.mu.Lock()
+	s.transactionMu.Lock()
 }
 
 func (s *PodInMemoryStorage) EndTransaction() {
-	s.mu.Unlock()
+	s.transactionMu.Unlock()
 }
 
 func (s *PodInMemoryStorage) GetPods() (core.PodList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
-	return s.pods, s.podBroadcaster
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pods := s.podList
+	pods.Items = s.pods.list()
+	return pods, s.podBroadcaster
 }
 
 func (s *PodInMemoryStorage) StorePods(pods core.PodList, events []metav1.WatchEvent) {
-	s.pods = pods
-	for _, e := range events {
-		s.podEventChan <- e
-	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	s.pods.replace(pods.Items)
+	pods.Items = nil
+	s.podList = pods
+	snapshots := snapshotEvents(events)
+	s.mu.Unlock()
+	sendEvents(s.podEventChan, snapshots)
 }
 
 func (s *PodInMemoryStorage) DeletePods(events []metav1.WatchEvent) {
-	s.pods = core.PodList{}
-	for _, e := range events {
-		e.Type = "DELETED"
-		s.podEventChan <- e
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	s.pods.replace(nil)
+	s.podList = core.PodList{}
+	snapshots := snapshotEvents(events)
+	s.mu.Unlock()
+	for i := range snapshots {
+		snapshots[i].Type = "DELETED"
 	}
+	sendEvents(s.podEventChan, snapshots)
 }
 
 func (s *PodInMemoryStorage) GetPod(podName string) core.Pod {
-	var u core.Pod
-	for _, element := range s.pods.Items {
-		if element.Name == podName {
-			klog.V(8).Infof("Found pod %s", podName)
-			u = element
-		}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, found := s.pods.get(podName)
+	if found {
+		klog.V(8).Infof("Found pod %s", podName)
 	}
 	return u
 }
 
+func (s *PodInMemoryStorage) GetPodsOnNode(nodeName string) []core.Pod {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pods.byIndex(podsByNodeIndex, nodeName)
+}
+
 func (s *PodInMemoryStorage) UpdatePod(podName string, newValues core.Pod) {
-	// Find index, and replace
-	var index int = -1
-	for i, element := range s.pods.Items {
-		if element.Name == podName {
-			klog.V(8).Info("Found pod %s\n", podName)
-			index = i
-			break
-		}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	if !s.pods.update(podName, newValues) {
+		// Unknown pod, do nothing
+		s.mu.Unlock()
+		return
 	}
-	if index != -1 {
-		// Found in list => update
-		s.pods.Items[index] = newValues
-
-		// Fire modified watch event
-		s.podEventChan <- metav1.WatchEvent{
-			Type:   "MODIFIED",
-			Object: runtime.RawExtension{Object: &s.pods.Items[index]},
-		}
+	klog.V(8).Infof("Found pod %s", podName)
+	s.mu.Unlock()
+	// Fire modified watch event
+	s.podEventChan <- snapshotEvent(metav1.WatchEvent{
+		Type:   "MODIFIED",
+		Object: runtime.RawExtension{Object: &newValues},
+	})
+}
+
+func (s *PodInMemoryStorage) DeletePod(podName string) core.Pod {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	deletedPod, found := s.pods.remove(podName)
+	s.mu.Unlock()
+	if found {
+		// Fire deleted watch event
+		s.podEventChan <- snapshotEvent(metav1.WatchEvent{
+			Type:   "DELETED",
+			Object: runtime.RawExtension{Object: &deletedPod},
+		})
+	}
+	return deletedPod
+}
+
+func (s *PodInMemoryStorage) Reset() int {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.Lock()
+	deletedPods := s.pods.removeAll()
+	s.podList = core.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}, Items: nil}
+	s.nextResourceId = 1
+	s.failedPodBuffer.Clear()
+	s.bindedPodBuffer.Clear()
+	s.podsToBePlaced.Clear()
+	s.mu.Unlock()
+	// The removed pods are no longer stored, so they can be sent without copying them
+	for i := range deletedPods {
+		s.podEventChan <- metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &deletedPods[i]}}
 	}
-	// else do nothing
+	return len(deletedPods)
 }
 
 func (s *PodInMemoryStorage) FailedPodBuffer() storage2.Buffer[misim.BindingFailureInformation] {
@@ -103,12 +161,20 @@ func (s *PodInMemoryStorage) PodsUpdateChannel() storage2.ChannelWrapper[misim.P
 	return &s.podsUpdateChannel
 }
 
-func NewPodInMemoryStorage() PodInMemoryStorage {
+func NewPodInMemoryStorage(ctx context.Context) PodInMemoryStorage {
 	podEventChan := make(chan metav1.WatchEvent, 500)
 	return PodInMemoryStorage{
-		pods:           core.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}, Items: nil},
+		podList: core.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}, Items: nil},
+		pods: newIndexedObjects[core.Pod](map[string]indexFunc[core.Pod]{
+			podsByNodeIndex: func(pod *core.Pod) []string {
+				if pod.Spec.NodeName == "" {
+					return nil
+				}
+				return []string{pod.Spec.NodeName}
+			},
+		}),
 		podEventChan:   podEventChan,
-		podBroadcaster: broadcast.NewBroadcastServer(context.TODO(), "PodBroadcaster", podEventChan),
+		podBroadcaster: broadcast.NewBroadcastServer(ctx, "PodBroadcaster", podEventChan),
 		nextResourceId: 1,
 
 		failedPodBuffer:   NewInMemBuffer[misim.BindingFailureInformation](),
@@ -0,0 +1,57 @@
+package inmemorystorage
+
+import (
+	"context"
+	"go-kube/internal/broadcast"
+	scheduling "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sync"
+)
+
+type PriorityClassInMemoryStorage struct {
+	mu sync.RWMutex
+
+	priorityClasses          scheduling.PriorityClassList
+	priorityClassEventChan   chan metav1.WatchEvent
+	priorityClassBroadcaster *broadcast.BroadcastServer[metav1.WatchEvent]
+}
+
+// PriorityClassStorage interface
+
+func (s *PriorityClassInMemoryStorage) StorePriorityClasses(pcs scheduling.PriorityClassList, events []metav1.WatchEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priorityClasses = *pcs.DeepCopy()
+	for _, event := range events {
+		s.priorityClassEventChan <- snapshotEvent(event)
+	}
+}
+
+func (s *PriorityClassInMemoryStorage) GetPriorityClasses() (scheduling.PriorityClassList, *broadcast.BroadcastServer[metav1.WatchEvent]) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return *s.priorityClasses.DeepCopy(), s.priorityClassBroadcaster
+}
+
+func (s *PriorityClassInMemoryStorage) Reset() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.priorityClasses.Items {
+		s.priorityClassEventChan <- snapshotEvent(metav1.WatchEvent{Type: "DELETED", Object: runtime.RawExtension{Object: &s.priorityClasses.Items[i]}})
+	}
+	deleted := len(s.priorityClasses.Items)
+	s.priorityClasses = scheduling.PriorityClassList{TypeMeta: metav1.TypeMeta{Kind: "PriorityClassList", APIVersion: "scheduling.k8s.io/v1"}, Items: nil}
+	return deleted
+}
+
+// Constructors
+
+func NewPriorityClassInMemoryStorage(ctx context.Context) PriorityClassInMemoryStorage {
+	priorityClassEventChan := make(chan metav1.WatchEvent, 500)
+	return PriorityClassInMemoryStorage{
+		priorityClasses:          scheduling.PriorityClassList{TypeMeta: metav1.TypeMeta{Kind: "PriorityClassList", APIVersion: "scheduling.k8s.io/v1"}, Items: nil},
+		priorityClassEventChan:   priorityClassEventChan,
+		priorityClassBroadcaster: broadcast.NewBroadcastServer(ctx, "PriorityClassBroadcaster", priorityClassEventChan),
+	}
+}
@@ -0,0 +1,28 @@
+package inmemorystorage
+
+import (
+	"context"
+	"go-kube/internal/broadcast"
+	"go-kube/pkg/misim"
+)
+
+type ProgressInMemoryStorage struct {
+	progressChan        chan misim.StreamMessage
+	progressBroadcaster *broadcast.BroadcastServer[misim.StreamMessage]
+}
+
+func (s *ProgressInMemoryStorage) Publish(update misim.StreamMessage) {
+	s.progressChan <- update
+}
+
+func (s *ProgressInMemoryStorage) GetProgressBroadcaster() *broadcast.BroadcastServer[misim.StreamMessage] {
+	return s.progressBroadcaster
+}
+
+func NewProgressInMemoryStorage(ctx context.Context) ProgressInMemoryStorage {
+	progressChan := make(chan misim.StreamMessage, 500)
+	return ProgressInMemoryStorage{
+		progressChan:        progressChan,
+		progressBroadcaster: broadcast.NewBroadcastServer(ctx, "ProgressBroadcaster", progressChan),
+	}
+}
@@ -3,23 +3,39 @@ package inmemorystorage
 import (
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sync"
 )
 
 type StatusConfigMapInMemoryStorage struct {
+	mu              sync.RWMutex
 	statusConfigMap core.ConfigMap
 }
 
 func (s *StatusConfigMapInMemoryStorage) GetStatusConfigMap() core.ConfigMap {
-	return s.statusConfigMap
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return *s.statusConfigMap.DeepCopy()
 }
 
 func (s *StatusConfigMapInMemoryStorage) StoreStatusConfigMap(configMap core.ConfigMap) {
-	s.statusConfigMap = configMap
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusConfigMap = *configMap.DeepCopy()
+}
+
+func (s *StatusConfigMapInMemoryStorage) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statusConfigMap = emptyStatusConfigMap()
+}
+
+func emptyStatusConfigMap() core.ConfigMap {
+	return core.ConfigMap{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-autoscaler-status", Namespace: "kube-system"}}
 }
 
 func NewStatusMapInMemoryStorage() StatusConfigMapInMemoryStorage {
 	return StatusConfigMapInMemoryStorage{
-		statusConfigMap: core.ConfigMap{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
-			ObjectMeta: metav1.ObjectMeta{Name: "cluster-autoscaler-status", Namespace: "kube-system"}},
+		statusConfigMap: emptyStatusConfigMap(),
 	}
 }
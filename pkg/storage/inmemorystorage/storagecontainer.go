@@ -0,0 +1,56 @@
+package inmemorystorage
+
+import (
+	"context"
+	"go-kube/internal/broadcast"
+	"go-kube/pkg/storage"
+)
+
+// Creates the storages of one virtual cluster, their broadcasters stop when the context is done.
+// The cluster starts with the passed namespaces and keeps at most timelineMaxEvents timeline events.
+func NewStorageContainer(ctx context.Context, namespaces []string, timelineMaxEvents int) storage.StorageContainer {
+	var podStorage = NewPodInMemoryStorage(ctx)
+	var nodeStorage = NewNodeInMemoryStorage(ctx)
+	var namespaceStorage = NewNamespaceInMemoryStorage(ctx, namespaces)
+	var daemonSetStorage = NewDaemonSetInMemoryStorage(ctx)
+	var priorityClassStorage = NewPriorityClassInMemoryStorage(ctx)
+	var configMapStorage = NewConfigMapInMemoryStorage(ctx)
+	var machineStorage = NewMachineInMemoryStorage(ctx)
+	var machineSetStorage = NewMachineSetInMemoryStorage(ctx, &nodeStorage, &machineStorage)
+	var statusConfigMapStorage = NewStatusMapInMemoryStorage()
+	var podIdStorage = NewIdInMemoryStorage()
+	var machineIdStorage = NewIdInMemoryStorage()
+	var nodeIdStorage = NewIdInMemoryStorage()
+	var objectIdStorage = NewIdInMemoryStorage()
+	var adapterStateStorage = NewAdapterStateInMemoryStorage()
+	var eventStorage = NewEventInMemoryStorage()
+	var progressStorage = NewProgressInMemoryStorage(ctx)
+	var timelineStorage = NewTimelineInMemoryStorage(timelineMaxEvents)
+	var componentStorage = NewComponentInMemoryStorage(ctx)
+	var apiUsageStorage = NewApiUsageInMemoryStorage()
+	var faultStorage = NewFaultInMemoryStorage()
+
+	return storage.StorageContainer{
+		Cluster:         broadcast.ClusterFromContext(ctx),
+		Pods:            &podStorage,
+		Nodes:           &nodeStorage,
+		Namespaces:      &namespaceStorage,
+		DaemonSets:      &daemonSetStorage,
+		PriorityClasses: &priorityClassStorage,
+		ConfigMaps:      &configMapStorage,
+		Machines:        &machineStorage,
+		MachineSets:     &machineSetStorage,
+		StatusConfigMap: &statusConfigMapStorage,
+		PodIds:          &podIdStorage,
+		MachineIds:      &machineIdStorage,
+		NodeIds:         &nodeIdStorage,
+		ObjectIds:       &objectIdStorage,
+		AdapterState:    &adapterStateStorage,
+		Events:          &eventStorage,
+		Progress:        &progressStorage,
+		Timeline:        &timelineStorage,
+		Components:      &componentStorage,
+		ApiUsage:        &apiUsageStorage,
+		Faults:          &faultStorage,
+	}
+}
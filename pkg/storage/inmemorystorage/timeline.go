@@ -0,0 +1,97 @@
+package inmemorystorage
+
+import (
+	"go-kube/pkg/timeline"
+	"sync"
+	"time"
+)
+
+type TimelineInMemoryStorage struct {
+	mu    sync.RWMutex
+	round int
+	// Ring buffer of the last maxEvents events, oldest is the position of the oldest one once it is full
+	events    []timeline.Event
+	oldest    int
+	maxEvents int
+	// Event names recorded per pod in the current round
+	recorded map[string]map[timeline.EventName]bool
+}
+
+func (s *TimelineInMemoryStorage) StartRound() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.round++
+	s.recorded = make(map[string]map[timeline.EventName]bool)
+	return s.round
+}
+
+func (s *TimelineInMemoryStorage) GetRound() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.round
+}
+
+func (s *TimelineInMemoryStorage) Record(pod string, name timeline.EventName, attributes map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(pod, name, time.Now(), nil, attributes)
+}
+
+func (s *TimelineInMemoryStorage) RecordOnce(pod string, name timeline.EventName, attributes map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.recorded[pod][name] {
+		return
+	}
+	s.record(pod, name, time.Now(), nil, attributes)
+}
+
+func (s *TimelineInMemoryStorage) RecordSpan(pod string, name timeline.EventName, start time.Time, end time.Time, attributes map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(pod, name, start, &end, attributes)
+}
+
+func (s *TimelineInMemoryStorage) record(pod string, name timeline.EventName, start time.Time, end *time.Time, attributes map[string]string) {
+	event := timeline.Event{Round: s.round, Pod: pod, Name: name, Start: start, End: end, Attributes: attributes}
+	if len(s.events) < s.maxEvents {
+		s.events = append(s.events, event)
+	} else {
+		// The oldest event is replaced
+		s.events[s.oldest] = event
+		s.oldest = (s.oldest + 1) % s.maxEvents
+	}
+	if s.recorded[pod] == nil {
+		s.recorded[pod] = make(map[timeline.EventName]bool)
+	}
+	s.recorded[pod][name] = true
+}
+
+func (s *TimelineInMemoryStorage) GetEvents() []timeline.Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	events := make([]timeline.Event, 0, len(s.events))
+	events = append(events, s.events[s.oldest:]...)
+	return append(events, s.events[:s.oldest]...)
+}
+
+func (s *TimelineInMemoryStorage) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.round = 0
+	s.events = nil
+	s.oldest = 0
+	s.recorded = make(map[string]map[timeline.EventName]bool)
+}
+
+// Keeps the last maxEvents events, older ones are dropped. Without a positive
+// maxEvents, timeline.DefaultMaxEvents are kept.
+func NewTimelineInMemoryStorage(maxEvents int) TimelineInMemoryStorage {
+	if maxEvents <= 0 {
+		maxEvents = timeline.DefaultMaxEvents
+	}
+	return TimelineInMemoryStorage{
+		maxEvents: maxEvents,
+		recorded:  make(map[string]map[timeline.EventName]bool),
+	}
+}
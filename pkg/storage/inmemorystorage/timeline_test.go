@@ -0,0 +1,49 @@
+package inmemorystorage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go-kube/pkg/timeline"
+)
+
+func TestTimelineKeepsLastEvents(t *testing.T) {
+	storage := NewTimelineInMemoryStorage(3)
+	storage.StartRound()
+	for i := 0; i < 5; i++ {
+		storage.Record(fmt.Sprintf("pod-%d", i), timeline.Received, nil)
+	}
+	events := storage.GetEvents()
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	for i, event := range events {
+		if want := fmt.Sprintf("pod-%d", i+2); event.Pod != want {
+			t.Fatalf("event %d is of %s, want %s", i, event.Pod, want)
+		}
+		if !event.IsInstant() {
+			t.Fatalf("event %d has an end", i)
+		}
+	}
+
+	storage.RecordSpan("pod-5", timeline.ScaleUpWait, time.Now(), time.Now(), nil)
+	if events := storage.GetEvents(); events[2].IsInstant() || events[0].Pod != "pod-3" {
+		t.Fatalf("span was not recorded as the newest event: %+v", events)
+	}
+
+	storage.Reset()
+	if events := storage.GetEvents(); len(events) != 0 {
+		t.Fatalf("got %d events after reset", len(events))
+	}
+}
+
+// Storages created without the validated config, e.g. by tests, must not panic on the first event
+func TestTimelineWithoutLimit(t *testing.T) {
+	storage := NewTimelineInMemoryStorage(0)
+	storage.StartRound()
+	storage.Record("pod-0", timeline.Received, nil)
+	if events := storage.GetEvents(); len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+}
@@ -0,0 +1,35 @@
+package inmemorystorage
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Returns the event with a deep copy of its object. Events are encoded by the watchers
+// after they have been sent, so they must not share any state with the storage or the caller.
+func snapshotEvent(event metav1.WatchEvent) metav1.WatchEvent {
+	if event.Object.Object != nil {
+		event.Object.Object = event.Object.Object.DeepCopyObject()
+	}
+	if event.Object.Raw != nil {
+		event.Object.Raw = append([]byte(nil), event.Object.Raw...)
+	}
+	return event
+}
+
+// Returns snapshots of the events, see snapshotEvent
+func snapshotEvents(events []metav1.WatchEvent) []metav1.WatchEvent {
+	snapshots := make([]metav1.WatchEvent, len(events))
+	for i := range events {
+		snapshots[i] = snapshotEvent(events[i])
+	}
+	return snapshots
+}
+
+// Sends the events to the broadcaster of a storage. The storages call it after releasing
+// their lock, so readers never wait for a slow broadcaster. Writers hold the sendMu of the
+// storage until their events are sent, so the events keep the order of the changes.
+func sendEvents(channel chan<- metav1.WatchEvent, events []metav1.WatchEvent) {
+	for _, event := range events {
+		channel <- event
+	}
+}
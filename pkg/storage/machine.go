@@ -14,6 +14,10 @@ type MachineStorage interface {
 	// Gets a single machine
 	// GetMachine(w http.ResponseWriter, r *http.Request)
 	GetMachine(machineName string) cluster.Machine
+	// Returns the machines owned by the machine set with the passed name
+	GetMachinesOfMachineSet(machineSetName string) []cluster.Machine
+	// Returns the machine whose nodeRef points to the node with the passed name
+	GetMachineOfNode(nodeName string) (cluster.Machine, bool)
 	// Deletes the machine
 	DeleteMachine(machineName string) cluster.Machine
 	AddMachine(cluster.Machine)
@@ -22,4 +26,7 @@ type MachineStorage interface {
 	PutMachine(machineName string, machine cluster.Machine) cluster.Machine
 	IncrementMachineCount()
 	GetMachineCount() int
+	// Removes all machines and resets the machine count, a DELETED event is sent for every machine.
+	// Returns the number of removed machines.
+	Reset() int
 }
@@ -21,4 +21,7 @@ type MachineSetStorage interface {
 	IsDownscalingPossible() bool
 	// Get scale
 	GetMachineSetsScale(machineSetName string) v1.Scale
+	// Removes all machinesets, a DELETED event is sent for every machineset.
+	// Returns the number of removed machinesets.
+	Reset() int
 }
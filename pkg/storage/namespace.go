@@ -10,7 +10,10 @@ type NamespaceStorage interface {
 	// Returns the current namespaces
 	GetNamespaces() (v1.NamespaceList, *broadcast.BroadcastServer[metav1.WatchEvent])
 	// Stores a namespace list in the storage
-	StoreNamespaces(ns v1.NamespaceList)
+	StoreNamespaces(ns v1.NamespaceList, events []metav1.WatchEvent)
 	// Returns a single namespace by name
 	GetNamespace(name string) v1.Namespace
+	// Restores the initial namespaces, a DELETED event is sent for every other namespace.
+	// Returns the number of removed namespaces.
+	Reset() int
 }
@@ -7,6 +7,9 @@ import (
 )
 
 type NodeStorage interface {
+	// Held by the controllers while they read, change and store the nodes
+	BeginTransaction()
+	EndTransaction()
 	// Stores a nodelist in the storage
 	StoreNodes(nodes v1.NodeList, events []metav1.WatchEvent)
 	// Retrieves the current nodeList from the storage
@@ -17,6 +20,9 @@ type NodeStorage interface {
 	GetNode(name string) v1.Node
 	// Deletes a node from the node list
 	DeleteNode(name string) v1.Node
+	// Removes all nodes and clears the buffers, a DELETED event is sent for every node.
+	// Returns the number of removed nodes.
+	Reset() int
 	// Adds a node
 	AddNode(v1.Node)
 	// Channel for Node Upscaling
@@ -18,9 +18,17 @@ type PodStorage interface {
 	DeletePods(events []metav1.WatchEvent)
 	// Get Pod by name
 	GetPod(podName string) v1.Pod
+	// Returns the pods bound to the node with the passed name
+	GetPodsOnNode(nodeName string) []v1.Pod
 	// Updates the pod with the passed name
 	// and triggers watch event
 	UpdatePod(podName string, newValues v1.Pod)
+	// Removes the pod with the passed name
+	// and triggers watch event
+	DeletePod(podName string) v1.Pod
+	// Removes all pods and clears the buffers, a DELETED event is sent for every pod.
+	// Returns the number of removed pods.
+	Reset() int
 
 	// Buffer for failed pods
 	FailedPodBuffer() Buffer[misim.BindingFailureInformation]
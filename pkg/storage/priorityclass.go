@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"go-kube/internal/broadcast"
+	scheduling "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type PriorityClassStorage interface {
+	// Stores a priority class list in the storage
+	StorePriorityClasses(pcs scheduling.PriorityClassList, events []metav1.WatchEvent)
+	// Returns the current priority classes
+	GetPriorityClasses() (scheduling.PriorityClassList, *broadcast.BroadcastServer[metav1.WatchEvent])
+	// Removes all priority classes, a DELETED event is sent for every priority class.
+	// Returns the number of removed priority classes.
+	Reset() int
+}
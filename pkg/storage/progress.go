@@ -0,0 +1,13 @@
+package storage
+
+import (
+	"go-kube/internal/broadcast"
+	"go-kube/pkg/misim"
+)
+
+type ProgressStorage interface {
+	// Publishes a progress update (binding, failure, node change, eviction) of the current round
+	Publish(update misim.StreamMessage)
+	// Broadcaster for progress updates
+	GetProgressBroadcaster() *broadcast.BroadcastServer[misim.StreamMessage]
+}
@@ -9,4 +9,6 @@ type StatusConfigMapStorage interface {
 	StoreStatusConfigMap(core.ConfigMap)
 	// Returns the current status config map
 	GetStatusConfigMap() core.ConfigMap
+	// Restores the empty status config map
+	Reset()
 }
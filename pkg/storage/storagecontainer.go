@@ -1,15 +1,27 @@
 package storage
 
 type StorageContainer struct {
+	// Name of the virtual cluster the storages belong to
+	Cluster         string
 	Pods            PodStorage
 	Nodes           NodeStorage
 	Namespaces      NamespaceStorage
 	DaemonSets      DaemonSetStorage
+	PriorityClasses PriorityClassStorage
+	ConfigMaps      ConfigMapStorage
 	Machines        MachineStorage
 	MachineSets     MachineSetStorage
 	StatusConfigMap StatusConfigMapStorage
 	PodIds          IdStorage
 	MachineIds      IdStorage
-	AdapterState    AdapterStateStorage
-	Events          EventStorage
+	NodeIds         IdStorage
+	// Resource versions of the objects loaded from manifests that have no own id storage
+	ObjectIds    IdStorage
+	AdapterState AdapterStateStorage
+	Events       EventStorage
+	Progress     ProgressStorage
+	Timeline     TimelineStorage
+	Components   ComponentStorage
+	ApiUsage     ApiUsageStorage
+	Faults       FaultStorage
 }
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"go-kube/pkg/timeline"
+	"time"
+)
+
+type TimelineStorage interface {
+	// Starts a new scheduling round, later events belong to it
+	StartRound() int
+	// Returns the current scheduling round, 0 before the first one
+	GetRound() int
+	// Records an event of a pod in the current round
+	Record(pod string, name timeline.EventName, attributes map[string]string)
+	// Records an event only if the pod has no event with the same name in the current round
+	RecordOnce(pod string, name timeline.EventName, attributes map[string]string)
+	// Records a span of a pod in the current round, e.g. waiting for a scale-up
+	RecordSpan(pod string, name timeline.EventName, start time.Time, end time.Time, attributes map[string]string)
+	// Returns the recorded events in the order they were recorded, the oldest are dropped beyond a maximum
+	GetEvents() []timeline.Event
+	// Removes all events and restarts the rounds
+	Reset()
+}
@@ -0,0 +1,76 @@
+package timeline
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Trace in the Chrome trace event format, which can be opened with chrome://tracing or Perfetto.
+// Each round is shown as process and each pod as thread.
+type ChromeTrace struct {
+	TraceEvents     []ChromeTraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string             `json:"displayTimeUnit"`
+}
+
+type ChromeTraceEvent struct {
+	Name  string `json:"name"`
+	Phase string `json:"ph"`
+	// Microseconds since the Unix epoch
+	Timestamp int64 `json:"ts"`
+	// Microseconds, only for complete events
+	Duration int64 `json:"dur,omitempty"`
+	// Scope of instant events
+	Scope     string            `json:"s,omitempty"`
+	ProcessId int               `json:"pid"`
+	ThreadId  int               `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+func ToChromeTrace(events []Event) ChromeTrace {
+	trace := ChromeTrace{TraceEvents: make([]ChromeTraceEvent, 0, len(events)), DisplayTimeUnit: "ms"}
+	threadIds := make(map[int]map[string]int)
+	for _, event := range events {
+		pods, found := threadIds[event.Round]
+		if !found {
+			pods = make(map[string]int)
+			threadIds[event.Round] = pods
+			trace.TraceEvents = append(trace.TraceEvents, ChromeTraceEvent{
+				Name: "process_name", Phase: "M", ProcessId: event.Round,
+				Args: map[string]string{"name": "Round " + strconv.Itoa(event.Round)},
+			})
+		}
+		threadId, found := pods[event.Pod]
+		if !found {
+			threadId = len(pods) + 1
+			pods[event.Pod] = threadId
+			trace.TraceEvents = append(trace.TraceEvents, ChromeTraceEvent{
+				Name: "thread_name", Phase: "M", ProcessId: event.Round, ThreadId: threadId,
+				Args: map[string]string{"name": event.Pod},
+			})
+		}
+
+		traceEvent := ChromeTraceEvent{
+			Name:      string(event.Name),
+			Timestamp: event.Start.UnixMicro(),
+			ProcessId: event.Round,
+			ThreadId:  threadId,
+			Args:      event.Attributes,
+		}
+		if event.IsInstant() {
+			traceEvent.Phase = "i"
+			traceEvent.Scope = "t"
+		} else {
+			traceEvent.Phase = "X"
+			traceEvent.Duration = event.End.Sub(event.Start).Microseconds()
+		}
+		trace.TraceEvents = append(trace.TraceEvents, traceEvent)
+	}
+	sort.SliceStable(trace.TraceEvents, func(i, j int) bool {
+		// Metadata first, then by time
+		if (trace.TraceEvents[i].Phase == "M") != (trace.TraceEvents[j].Phase == "M") {
+			return trace.TraceEvents[i].Phase == "M"
+		}
+		return trace.TraceEvents[i].Timestamp < trace.TraceEvents[j].Timestamp
+	})
+	return trace
+}
@@ -0,0 +1,162 @@
+package timeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Traces in the JSON encoding of the OpenTelemetry protocol (OTLP), as accepted by
+// collectors on /v1/traces. Each round is a trace and each pod a span within it,
+// its steps are span events and waiting for a scale-up is a child span.
+type OtlpTraces struct {
+	ResourceSpans []OtlpResourceSpans `json:"resourceSpans"`
+}
+
+type OtlpResourceSpans struct {
+	Resource   OtlpResource     `json:"resource"`
+	ScopeSpans []OtlpScopeSpans `json:"scopeSpans"`
+}
+
+type OtlpResource struct {
+	Attributes []OtlpKeyValue `json:"attributes"`
+}
+
+type OtlpScopeSpans struct {
+	Scope OtlpScope  `json:"scope"`
+	Spans []OtlpSpan `json:"spans"`
+}
+
+type OtlpScope struct {
+	Name string `json:"name"`
+}
+
+type OtlpSpan struct {
+	TraceId      string `json:"traceId"`
+	SpanId       string `json:"spanId"`
+	ParentSpanId string `json:"parentSpanId,omitempty"`
+	Name         string `json:"name"`
+	// SPAN_KIND_INTERNAL
+	Kind int `json:"kind"`
+	// Nanoseconds since the Unix epoch, encoded as string like all 64 bit integers in OTLP JSON
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []OtlpKeyValue  `json:"attributes,omitempty"`
+	Events            []OtlpSpanEvent `json:"events,omitempty"`
+}
+
+type OtlpSpanEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []OtlpKeyValue `json:"attributes,omitempty"`
+}
+
+type OtlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value OtlpAnyValue `json:"value"`
+}
+
+type OtlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func ToOtlp(events []Event) OtlpTraces {
+	type podKey struct {
+		round int
+		pod   string
+	}
+	grouped := make(map[podKey][]Event)
+	var keys []podKey
+	for _, event := range events {
+		key := podKey{event.Round, event.Pod}
+		if _, found := grouped[key]; !found {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], event)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].round < keys[j].round })
+
+	spans := make([]OtlpSpan, 0, len(keys))
+	for _, key := range keys {
+		traceId := otlpId(16, "round", strconv.Itoa(key.round))
+		podSpan := OtlpSpan{
+			TraceId:    traceId,
+			SpanId:     otlpId(8, strconv.Itoa(key.round), key.pod),
+			Name:       key.pod,
+			Kind:       1,
+			Attributes: []OtlpKeyValue{otlpAttribute("k8s.pod.name", key.pod), otlpAttribute("misim.round", strconv.Itoa(key.round))},
+		}
+		var start, end time.Time
+		for _, event := range grouped[key] {
+			eventEnd := event.Start
+			if !event.IsInstant() {
+				eventEnd = *event.End
+			}
+			if start.IsZero() || event.Start.Before(start) {
+				start = event.Start
+			}
+			if eventEnd.After(end) {
+				end = eventEnd
+			}
+			if event.IsInstant() {
+				podSpan.Events = append(podSpan.Events, OtlpSpanEvent{
+					TimeUnixNano: otlpTime(event.Start),
+					Name:         string(event.Name),
+					Attributes:   otlpAttributes(event.Attributes),
+				})
+				continue
+			}
+			spans = append(spans, OtlpSpan{
+				TraceId:           traceId,
+				SpanId:            otlpId(8, strconv.Itoa(key.round), key.pod, string(event.Name), otlpTime(event.Start)),
+				ParentSpanId:      podSpan.SpanId,
+				Name:              string(event.Name),
+				Kind:              1,
+				StartTimeUnixNano: otlpTime(event.Start),
+				EndTimeUnixNano:   otlpTime(*event.End),
+				Attributes:        otlpAttributes(event.Attributes),
+			})
+		}
+		podSpan.StartTimeUnixNano = otlpTime(start)
+		podSpan.EndTimeUnixNano = otlpTime(end)
+		spans = append(spans, podSpan)
+	}
+
+	return OtlpTraces{ResourceSpans: []OtlpResourceSpans{{
+		Resource:   OtlpResource{Attributes: []OtlpKeyValue{otlpAttribute("service.name", "misim-k8s-adapter")}},
+		ScopeSpans: []OtlpScopeSpans{{Scope: OtlpScope{Name: "go-kube/timeline"}, Spans: spans}},
+	}}}
+}
+
+// Derives a stable id of the passed length in bytes, so repeated exports yield the same ids
+func otlpId(length int, parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:length])
+}
+
+func otlpTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func otlpAttribute(key string, value string) OtlpKeyValue {
+	return OtlpKeyValue{Key: key, Value: OtlpAnyValue{StringValue: value}}
+}
+
+func otlpAttributes(attributes map[string]string) []OtlpKeyValue {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]OtlpKeyValue, len(keys))
+	for i, key := range keys {
+		result[i] = otlpAttribute(key, attributes[key])
+	}
+	return result
+}
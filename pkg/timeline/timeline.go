@@ -0,0 +1,39 @@
+package timeline
+
+import "time"
+
+// Step in the scheduling of a pod
+type EventName string
+
+const (
+	// The pod was received from the simulation as pod to be placed
+	Received EventName = "Received"
+	// The scheduler reported a result for the pod for the first time in the round
+	FirstSchedulingAttempt EventName = "FirstSchedulingAttempt"
+	// The scheduler patched the status of the pod because it could not be placed
+	SchedulingFailed EventName = "SchedulingFailed"
+	// The pod waited for the cluster-autoscaler to add a node, the only event with a duration
+	ScaleUpWait EventName = "ScaleUpWait"
+	// The scheduler bound the pod to a node
+	Bound EventName = "Bound"
+)
+
+// Events kept per cluster if no other limit is configured. Enough for a round placing
+// 100k pods, which records three to five events per pod.
+const DefaultMaxEvents = 500000
+
+// Recorded step of a pod within a scheduling round
+type Event struct {
+	Round int       `json:"round"`
+	Pod   string    `json:"pod"`
+	Name  EventName `json:"name"`
+	Start time.Time `json:"start"`
+	// Nil for instant events
+	End *time.Time `json:"end,omitempty"`
+	// Details such as the node or the failure reason
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+func (e Event) IsInstant() bool {
+	return e.End == nil
+}
@@ -1,3 +1,3 @@
 #!/bin/bash
 
-go run ./*/**
+go run ./cmd/go-kube "$@"